@@ -4,7 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
 
 	"github.com/hirotoni/memo/components"
 	"github.com/hirotoni/memo/configs"
@@ -14,8 +14,6 @@ import (
 )
 
 const (
-	TIMEZONE        = "Asia/Tokyo"
-	FULL_LAYOUT     = "2006-01-02-Mon"
 	SHORT_LAYOUT    = "2006-01-02"
 	FILENAME_FORMAT = "%s.md"
 )
@@ -27,10 +25,9 @@ type App struct {
 }
 
 func NewApp() App {
-	gmw := markdown.NewGoldmarkWrapper()
 	conf := configs.LoadTomlConfig()
 	return App{
-		gmw:    gmw,
+		gmw:    conf.Gmw,
 		Config: conf,
 		repos:  repos.NewRepos(conf),
 	}
@@ -39,15 +36,24 @@ func NewApp() App {
 func (app *App) WithCustomConfig(conf configs.TomlConfig) {
 	app.Config = &conf
 	app.repos = repos.NewRepos(&conf)
+	if conf.Gmw != nil {
+		app.gmw = conf.Gmw
+	}
 }
 
 // Initialize initializes dirs and files
 func (app *App) Initialize() {
 	// dailymemo
 	initializeDir(app.Config.DailymemoDir())
-	initializeFile(app.Config.DailymemoTemplateFile(), components.TemplateDailymemo)
+	initializeFile(app.Config.DailymemoTemplateFile(), components.NewTemplateDailymemo(app.Config.DailymemoSections()))
 	// memoarchives
-	initializeDir(app.Config.MemoArchivesDir())
+	if app.Config.MemoArchives.Root == "" {
+		initializeDir(app.Config.MemoArchivesDir())
+	} else if _, err := os.Stat(app.Config.MemoArchivesDir()); err != nil {
+		// a root configured elsewhere is not created, in case it is a typo or on a drive not mounted
+		log.Printf("memo archives root is not available: %v", err)
+	}
+	initializeDir(filepath.Dir(app.Config.MemoArchivesIndexFile())) // the index stays in the base dir when memo archives are elsewhere
 	initializeFile(app.Config.MemoArchivesTemplateFile(), components.TemplateMemoArchives)
 	initializeFile(app.Config.MemoArchivesIndexFile(), components.TemplateMemoArchivesIndex)
 }
@@ -76,11 +82,3 @@ func initializeDir(dirpath string) {
 		log.Printf("directory initialized: %s", dirpath)
 	}
 }
-
-// OpenEditor opens editor
-func (app *App) OpenEditor(path string) {
-	cmd := exec.Command("code", path, "--folder-uri", app.Config.BaseDir)
-	if err := cmd.Run(); err != nil {
-		log.Fatal(err)
-	}
-}
@@ -3,8 +3,6 @@ package application
 import (
 	"fmt"
 	"log"
-	"os"
-	"os/exec"
 
 	"github.com/hirotoni/memo/configs"
 )
@@ -15,13 +13,7 @@ func (app *App) EditConfig() {
 		log.Fatal(err)
 	}
 
-	cmd := exec.Command("vim", configFile)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		log.Fatal(err)
-	}
+	app.runEditor(configFile, 0, FALLBACK_EDITOR_CONFIG)
 }
 
 func (app *App) ShowConfig() {
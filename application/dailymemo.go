@@ -6,85 +6,252 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/hirotoni/memo/components"
 	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/hirotoni/memo/review"
+	"github.com/yuin/goldmark/ast"
 )
 
-// GenerateMemo generates memo file
-func (app *App) GenerateMemo(date string, truncate bool) string {
-	filename := fmt.Sprintf(FILENAME_FORMAT, date)
+// GenerateMemo generates memo file of the date
+func (app *App) GenerateMemo(date time.Time, truncate bool) string {
+	filename := fmt.Sprintf(FILENAME_FORMAT, app.Config.DateLayout().Format(date))
 	targetFile := filepath.Join(app.Config.DailymemoDir(), filename)
 
 	log.Default().Printf("truncate: %v", truncate)
 
-	_, err := os.Stat(targetFile)
-	if errors.Is(err, os.ErrNotExist) || truncate {
-		f, err := os.Create(targetFile)
-		if err != nil {
-			log.Fatal(err)
+	existing, err := os.ReadFile(targetFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatal(err)
+	}
+	if err == nil && !truncate {
+		return targetFile
+	}
+
+	g := &generation{existing: existing, filename: filename}
+	content := app.generateMemo(date, g)
+	if err := os.WriteFile(targetFile, content, 0666); err != nil {
+		log.Fatal(err)
+	}
+	app.finishGeneration(g)
+
+	return targetFile
+}
+
+// generation is the state of a daily memo being generated.
+// Changes to other files are kept in it and written by finishGeneration only after the memo is saved,
+// so that the items moved out of the previous memo are not lost, and no showings are recorded, if the generation fails on the way.
+type generation struct {
+	filename    string             // basename of the memo
+	existing    []byte             // content of the memo being regenerated, nil if it is new
+	previous    *models.Dailymemo  // memo the sections are inherited from, nil if not found
+	previousDoc *markdown.Document // edits to the previous memo, leaving behind what was not moved
+	looked      bool               // the previous memo has been looked for
+	moved       []string           // headings of the sections moved from the previous memo
+	showings    []review.Showing   // memo archives shown in the memo
+}
+
+// previousMemoOf returns the memo the sections are inherited from, parsed once for all of them
+func (app *App) previousMemoOf(g *generation, date time.Time) (*models.Dailymemo, *markdown.Document) {
+	if !g.looked {
+		g.looked = true
+		if g.previous = app.previousMemo(date); g.previous != nil {
+			g.previousDoc = app.gmw.NewDocument(g.previous.Content)
 		}
-		defer f.Close()
+	}
+	return g.previous, g.previousDoc
+}
 
-		f.Write(app.generateMemo(date))
+// finishGeneration writes the changes to other files kept in the generation
+func (app *App) finishGeneration(g *generation) {
+	if err := review.AppendHistory(app.Config.HistoryFile(), g.showings); err != nil {
+		log.Fatal(err)
 	}
+	if len(g.moved) > 0 {
+		g.previous.Content = g.previousDoc.Bytes()
+		if err := app.repos.DailymemoRepo.Save(g.previous); err != nil {
+			log.Fatal(err)
+		}
+		for _, heading := range g.moved {
+			log.Printf("moved %q from %s", heading, g.previous.BaseName)
+		}
+	}
+}
 
+// GenerateMemos generates memo files from the date to the date in chronological order, so that each memo inherits from the one generated just before.
+// It returns the file of the last date.
+func (app *App) GenerateMemos(from, to time.Time, truncate bool) string {
+	var targetFile string
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		targetFile = app.GenerateMemo(date, truncate)
+	}
 	return targetFile
 }
 
-// generateMemo generates memo file
-func (app *App) generateMemo(date string) []byte {
-	t, err := app.repos.DailymemoRepo.Template()
+// generateMemo generates content of memo file
+func (app *App) generateMemo(date time.Time, g *generation) []byte {
+	t, err := app.repos.DailymemoRepo.TemplateFor(date)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if content, err := app.executeTemplate(t, date); err != nil {
+		// templates written before variables may have braces of their own
+		log.Printf("%s is used as is: %v", t.BaseName, err)
+	} else {
+		t.Content = content
+	}
+
+	doc := app.gmw.NewDocument(t.Content)
+	for _, section := range app.Config.DailymemoSections() {
+		switch section.Behavior {
+		case models.SECTIONBEHAVIOR_GENERATED:
+			err = doc.InsertTextAtHeadingStart(section.MarkdownHeading(), app.Config.DateLayout().Format(date))
+		case models.SECTIONBEHAVIOR_INHERIT:
+			err = app.inheritSection(doc, section, date, g)
+		case models.SECTIONBEHAVIOR_ARCHIVE:
+			err = app.appendMemoArchive(doc, section, date, g)
+		default:
+			continue
+		}
+		if errors.Is(err, markdown.ErrHeadingNotFound) {
+			log.Printf("%v in %s. run `memo template upgrade` to add it.", err, t.BaseName)
+			continue
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// inheritSection inherits items of the section from the memo of the day before the date
+func (app *App) inheritSection(doc *markdown.Document, section models.Section, date time.Time, g *generation) error {
+	heading := section.MarkdownHeading()
+
+	if section.Mode == models.INHERITMODE_MOVE && g.existing != nil {
+		// the items moved when the memo was generated before are no longer in the previous memo
+		_, nodes, err := app.gmw.NewDocument(g.existing).HangingNodes(heading)
+		if errors.Is(err, markdown.ErrAmbiguousHeading) {
+			return fmt.Errorf("%w in %s", err, g.filename)
+		}
+		if len(nodes) > 0 {
+			if err := doc.InsertNodesAtHeadingStart(heading, g.existing, nodes); err != nil {
+				return err
+			}
+		}
+	}
 
-	t.Content = app.gmw.InsertTextAtHeadingStart(t.Content, components.HEADING_NAME_TITLE, date)
-	t.Content = app.inheritHeading(t.Content, components.HEADING_NAME_TODOS)
-	t.Content = app.inheritHeading(t.Content, components.HEADING_NAME_WANTTODOS)
-	t.Content = app.appendMemoArchive(t.Content)
+	md, previous := app.previousMemoOf(g, date)
+	if md == nil {
+		log.Printf("previous memos were not found in previous %d days.", app.Config.DaysToSeek)
+		return nil
+	}
 
-	return t.Content
+	foundHeading, nodesToInsert, err := previous.HangingNodes(heading)
+	if errors.Is(err, markdown.ErrHeadingNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, md.BaseName)
+	}
+	if !section.KeepChecked {
+		nodesToInsert, _ = markdown.PruneCheckedTasks(nodesToInsert)
+	}
+	if len(nodesToInsert) == 0 {
+		return nil
+	}
+
+	switch section.Mode {
+	case models.INHERITMODE_LINK:
+		tag := previous.HeadingSlugs(app.Config.Slugger())[foundHeading]
+		link := app.gmw.BuildList(markdown.BuildLink(strings.TrimSuffix(md.BaseName, ".md"), md.BaseName+"#"+tag))
+		return doc.InsertTextAtHeadingStart(heading, link)
+	case models.INHERITMODE_MOVE:
+		// leave behind only after the items have found their place
+		if err := doc.InsertNodesAtHeadingStart(heading, md.Content, nodesToInsert); err != nil {
+			return err
+		}
+		app.leaveBehind(md, previous, section)
+		g.moved = append(g.moved, heading.Text)
+		return nil
+	default:
+		return doc.InsertNodesAtHeadingStart(heading, md.Content, nodesToInsert)
+	}
 }
 
-// inheritHeading inherits information of the specified heading from previous day's memo
-func (app *App) inheritHeading(tb []byte, heading markdown.Heading) []byte {
-	// previous days
-	today := time.Now()
+// previousMemo returns the latest memo before the date within the days to seek, or nil if not found
+func (app *App) previousMemo(date time.Time) *models.Dailymemo {
 	for i := range make([]int, app.Config.DaysToSeek) {
-		previousDay := today.AddDate(0, 0, -1*(i+1)).Format(FULL_LAYOUT)
+		previousDay := date.AddDate(0, 0, -1*(i+1))
 		md, err := app.repos.DailymemoRepo.FindByDate(previousDay)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
-				if i+1 == app.Config.DaysToSeek {
-					log.Printf("previous memos were not found in previous %d days.", app.Config.DaysToSeek)
-				}
 				continue
 			}
 			log.Fatal(err)
 		}
-
-		_, nodesToInsert := app.gmw.FindHeadingAndGetHangingNodes(md.Content, heading)
-		tb = app.gmw.InsertNodesAtHeadingStart(tb, heading, md.Content, nodesToInsert)
-		break
+		return md
 	}
+	return nil
+}
 
-	return tb
+// leaveBehind removes inherited items from the section of the previous memo, leaving only what was not inherited.
+// The previous memo is not saved until the memo inheriting the items is.
+func (app *App) leaveBehind(md *models.Dailymemo, previous *markdown.Document, section models.Section) {
+	heading := section.MarkdownHeading()
+	var rest []ast.Node
+	if !section.KeepChecked {
+		// pruning the checked tasks for inheritance removed the open ones from the tree
+		_, nodes, err := app.gmw.NewDocument(md.Content).HangingNodes(heading)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rest, _ = markdown.PruneOpenTasks(nodes)
+	}
+	if err := previous.ReplaceHangingNodes(heading, md.Content, rest); err != nil {
+		log.Fatal(err)
+	}
 }
 
-// appendMemoArchive appends memo archives
-func (app *App) appendMemoArchive(tb []byte) []byte {
-	picked := app.saveMemoArchives(true)
+// appendMemoArchive appends memo archive picked as of the date to the section
+func (app *App) appendMemoArchive(doc *markdown.Document, section models.Section, date time.Time, g *generation) error {
+	picked := app.saveMemoArchives(true, date)
+	for _, ma := range picked {
+		g.showings = append(g.showings, review.Showing{Date: date.Format(review.DATE_LAYOUT), Destination: ma.Destination, Dailymemo: g.filename})
+	}
 
-	// insert todays memo archive
-	if picked != nil && picked.Destination != "" {
-		chosenMemoArchive := markdown.BuildList(markdown.BuildLink(
-			picked.Text,
-			picked.Destination,
-		))
-		tb = app.gmw.InsertTextAtHeadingStart(tb, components.HEADING_NAME_TODAYSMEMOARCHIVE, chosenMemoArchive)
+	// insert todays memo archives
+	var items []string
+	for _, ma := range picked {
+		if ma.Destination != "" {
+			items = append(items, app.gmw.BuildList(markdown.BuildLink(ma.Text, ma.Destination)))
+		}
 	}
+	if len(items) == 0 {
+		return nil
+	}
+	return doc.InsertTextAtHeadingStart(section.MarkdownHeading(), strings.Join(items, "\n"))
+}
 
-	return tb
+// ParseDateRange parses a date range: `YYYY-MM-DD..YYYY-MM-DD`
+func ParseDateRange(s string) (time.Time, time.Time, error) {
+	fromStr, toStr, found := strings.Cut(s, "..")
+	if !found {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range: %s", s)
+	}
+	from, err := time.ParseInLocation(SHORT_LAYOUT, fromStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := time.ParseInLocation(SHORT_LAYOUT, toStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range: %s is before %s", toStr, fromStr)
+	}
+	return from, to, nil
 }
@@ -0,0 +1,114 @@
+package application
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/hirotoni/memo/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{name: "range", arg: "2026-08-30..2026-09-02", wantFrom: "2026-08-30", wantTo: "2026-09-02"},
+		{name: "single day", arg: "2026-09-01..2026-09-01", wantFrom: "2026-09-01", wantTo: "2026-09-01"},
+		{name: "reversed", arg: "2026-09-02..2026-09-01", wantErr: true},
+		{name: "no separator", arg: "2026-09-01", wantErr: true},
+		{name: "invalid date", arg: "2026-09-01..tomorrow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			from, to, err := ParseDateRange(tt.arg)
+			if tt.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.wantFrom, from.Format(SHORT_LAYOUT))
+			assert.Equal(tt.wantTo, to.Format(SHORT_LAYOUT))
+		})
+	}
+}
+
+func TestGenerateMemo_move(t *testing.T) {
+	assert := assert.New(t)
+	conf := configs.NewTomlConfig(t.TempDir(), 10, markdown.NewGoldmarkWrapper())
+	conf.Sections = []models.Section{{Heading: "todos", Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_MOVE}}
+	app := NewApp()
+	app.WithCustomConfig(*conf)
+	app.Initialize()
+
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	previous := filepath.Join(app.Config.DailymemoDir(), "2025-01-01-Wed.md")
+	assert.NoError(os.WriteFile(previous, []byte("## todos\n\n- [x] done\n- [ ] open\n"), 0666))
+
+	target := app.GenerateMemo(day2, false)
+	got, err := os.ReadFile(target)
+	assert.NoError(err)
+	assert.Equal("## todos\n\n- [ ] open\n", string(got))
+	left, err := os.ReadFile(previous)
+	assert.NoError(err)
+	assert.Equal("## todos\n\n- [x] done\n", string(left))
+
+	// the moved items are taken from the memo regenerated
+	app.GenerateMemo(day2, true)
+	got, err = os.ReadFile(target)
+	assert.NoError(err)
+	assert.Equal("## todos\n\n- [ ] open\n", string(got))
+}
+
+func TestGenerateMemo_literalBraces(t *testing.T) {
+	assert := assert.New(t)
+	conf := configs.NewTomlConfig(t.TempDir(), 10, markdown.NewGoldmarkWrapper())
+	conf.Sections = []models.Section{{Heading: "memos"}}
+	app := NewApp()
+	app.WithCustomConfig(*conf)
+	app.Initialize()
+
+	template := "## memos\n\n{{ user.name }} in a vue snippet\n"
+	assert.NoError(os.WriteFile(app.Config.DailymemoTemplateFile(), []byte(template), 0666))
+
+	got, err := os.ReadFile(app.GenerateMemo(time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), false))
+	assert.NoError(err)
+	assert.Equal(template, string(got))
+}
+
+func TestGenerateMemo_history(t *testing.T) {
+	assert := assert.New(t)
+	conf := configs.NewTomlConfig(t.TempDir(), 10, markdown.NewGoldmarkWrapper())
+	conf.Sections = []models.Section{{Heading: "today's memo archive", Behavior: models.SECTIONBEHAVIOR_ARCHIVE}}
+	app := NewApp()
+	app.WithCustomConfig(*conf)
+	app.Initialize()
+	assert.NoError(os.WriteFile(filepath.Join(app.Config.MemoArchivesDir(), "go.md"), []byte("# Go\n\n## generics\n"), 0666))
+
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local)
+	want := []review.Showing{{Date: "2025-01-02", Destination: "../memoarchives/go.md#generics", Dailymemo: "2025-01-02-Thu.md"}}
+	app.GenerateMemo(date, false)
+	got, err := review.LoadHistory(app.Config.HistoryFile())
+	assert.NoError(err)
+	assert.Equal(want, got)
+
+	// regenerating the memo does not count the memo archive shown twice on the day
+	app.GenerateMemo(date, true)
+	got, err = review.LoadHistory(app.Config.HistoryFile())
+	assert.NoError(err)
+	assert.Equal(want, got)
+	b, err := os.ReadFile(app.Config.HistoryFile())
+	assert.NoError(err)
+	assert.Equal(2, bytes.Count(b, []byte("\n")), "the history is only appended to")
+}
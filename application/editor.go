@@ -0,0 +1,118 @@
+package application
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hirotoni/memo/markdown"
+)
+
+const (
+	FALLBACK_EDITOR        = "code --goto {file}:{line} --folder-uri {basedir}" // used for memos when no editor is configured
+	FALLBACK_EDITOR_CONFIG = "vim +{line} {file}"                               // used for the config file when no editor is configured
+)
+
+// OpenEditor opens the file with the editor
+func (app *App) OpenEditor(path string) {
+	app.OpenEditorAt(path, 0)
+}
+
+// OpenEditorAt opens the file with the editor at the line. line 0 means the beginning of the file.
+func (app *App) OpenEditorAt(path string, line int) {
+	app.runEditor(path, line, FALLBACK_EDITOR)
+}
+
+func (app *App) runEditor(path string, line int, fallback string) {
+	args := editorCommand(app.editorTemplate(fallback), path, line, app.Config.BaseDir)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// editorTemplate returns the editor command in config, or the one built from $VISUAL or $EDITOR, or the fallback
+func (app *App) editorTemplate(fallback string) string {
+	if app.Config.Editor != "" {
+		return app.Config.Editor
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editorTemplateOf(editor)
+		}
+	}
+	return fallback
+}
+
+// editorTemplateOf adds placeholders to the editor command in the way the editor takes a line number
+func editorTemplateOf(editor string) string {
+	switch filepath.Base(strings.Fields(editor)[0]) {
+	case "vi", "vim", "nvim", "nano", "emacs", "emacsclient", "micro", "kak":
+		return editor + " +{line} {file}"
+	case "code", "code-insiders", "codium", "cursor":
+		return editor + " --goto {file}:{line}"
+	case "subl", "hx", "zed":
+		return editor + " {file}:{line}"
+	default:
+		return editor + " {file}"
+	}
+}
+
+// editorCommand expands placeholders {file}, {line} and {basedir} in the template, and splits it into arguments.
+// {file} is appended if the template does not have it.
+func editorCommand(template, path string, line int, basedir string) []string {
+	if line < 1 {
+		line = 1
+	}
+	replacer := strings.NewReplacer(
+		"{file}", path,
+		"{line}", strconv.Itoa(line),
+		"{basedir}", basedir,
+	)
+
+	fields := strings.Fields(template)
+	if !strings.Contains(template, "{file}") {
+		fields = append(fields, "{file}")
+	}
+
+	// split before expanding so that paths containing spaces stay in one argument
+	args := make([]string, 0, len(fields))
+	for _, f := range fields {
+		args = append(args, replacer.Replace(f))
+	}
+	return args
+}
+
+// LineUnderHeading returns the line number of the first empty line under the heading in the file, where you start writing.
+// The heading is a selector such as `memos > deploy`. It returns 0 if the heading is not found.
+func (app *App) LineUnderHeading(path string, heading string) int {
+	sel, err := markdown.ParseSelector(heading)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	headingLine, err := app.gmw.HeadingLine(b, sel)
+	if err != nil {
+		log.Printf("%v in %s", err, path)
+		return 0
+	}
+
+	lines := bytes.Split(b, []byte("\n"))
+	for i := headingLine; i < len(lines); i++ {
+		if len(bytes.TrimSpace(lines[i])) == 0 {
+			return i + 1
+		}
+	}
+	return headingLine + 1
+}
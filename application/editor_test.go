@@ -0,0 +1,58 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		line     int
+		expected []string
+	}{
+		{
+			name:     "all placeholders",
+			template: FALLBACK_EDITOR,
+			line:     12,
+			expected: []string{"code", "--goto", "/memo dir/daily/2026-09-01-Tue.md:12", "--folder-uri", "/memo dir"},
+		},
+		{
+			name:     "file appended when missing",
+			template: "nano -w",
+			line:     3,
+			expected: []string{"nano", "-w", "/memo dir/daily/2026-09-01-Tue.md"},
+		},
+		{
+			name:     "line defaults to first line",
+			template: "vim +{line} {file}",
+			line:     0,
+			expected: []string{"vim", "+1", "/memo dir/daily/2026-09-01-Tue.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := editorCommand(tt.template, "/memo dir/daily/2026-09-01-Tue.md", tt.line, "/memo dir")
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEditorTemplateOf(t *testing.T) {
+	tests := []struct {
+		editor   string
+		expected string
+	}{
+		{editor: "nvim", expected: "nvim +{line} {file}"},
+		{editor: "/usr/bin/vim", expected: "/usr/bin/vim +{line} {file}"},
+		{editor: "code --wait", expected: "code --wait --goto {file}:{line}"},
+		{editor: "ed", expected: "ed {file}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			assert.Equal(t, tt.expected, editorTemplateOf(tt.editor))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hirotoni/memo/components"
+)
+
+// Format rewrites the markdown files through the renderer in the configured style, and prints the files rewritten.
+// Directories are searched for markdown files, and daily memos and memo archives are formatted without paths.
+// With check, it only prints the diff of the files not formatted yet.
+// Files the renderer cannot write as they mean are left as they are.
+// It returns false if any file is left, or with check, if any file is not formatted.
+func (app *App) Format(paths []string, check bool) bool {
+	files := app.searchTargets()
+	if len(paths) > 0 {
+		files = markdownFiles(paths)
+	}
+
+	ok := true
+	for _, fpath := range files {
+		b, err := os.ReadFile(fpath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		relpath := fpath
+		if len(paths) == 0 {
+			relpath = app.relpath(fpath)
+		}
+		content, err := app.gmw.Format(b)
+		if err != nil {
+			log.Printf("skipped %s: %v", relpath, err)
+			ok = false
+			continue
+		}
+		if bytes.Equal(b, content) {
+			continue
+		}
+
+		if check {
+			fmt.Print(components.UnifiedDiff(relpath, relpath, b, content))
+			ok = false
+			continue
+		}
+		if err := os.WriteFile(fpath, content, 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(relpath)
+	}
+	return ok
+}
+
+// markdownFiles returns the files, and the markdown files in the directories except hidden ones such as .memo
+func markdownFiles(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch {
+			case d.IsDir() && p != path && strings.HasPrefix(d.Name(), "."):
+				return filepath.SkipDir
+			case !d.IsDir() && (p == path || filepath.Ext(p) == ".md"):
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	return files
+}
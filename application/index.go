@@ -0,0 +1,74 @@
+package application
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hirotoni/memo/index"
+)
+
+// loadIndex loads the search index and brings it up to date, re-indexing only files whose mtime or size changed.
+// The whole index is rebuilt when the config that memos are found under has changed.
+func (app *App) loadIndex() *index.Index {
+	idx, err := index.Load(app.Config.IndexFile(), app.Config.IndexFingerprint())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dailymemos, err := app.repos.DailymemoRepo.Filepaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var changed bool
+	var seen = map[string]bool{}
+	for _, fpath := range app.searchTargets() {
+		relpath, err := filepath.Rel(app.Config.BaseDir, fpath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		seen[relpath] = true
+
+		info, err := os.Stat(fpath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !idx.Stale(relpath, info.ModTime(), info.Size()) {
+			continue
+		}
+
+		doc := &index.Document{Path: relpath, ModTime: info.ModTime(), Size: info.Size()}
+		var content []byte
+		if slices.Contains(dailymemos, fpath) {
+			dm, err := app.repos.DailymemoRepo.Entry(fpath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			doc.Memos = app.repos.DailymemoRepo.MemosFromDailymemo(dm)
+			content = dm.Content
+		} else {
+			content, err = os.ReadFile(fpath)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		idx.Put(doc, string(content))
+		changed = true
+	}
+
+	for _, p := range idx.Paths() {
+		if !seen[p] {
+			idx.Remove(p)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := idx.Save(app.Config.IndexFile()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return idx
+}
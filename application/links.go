@@ -2,7 +2,7 @@ package application
 
 import (
 	"fmt"
-	"log"
+	"slices"
 	"strings"
 
 	"github.com/hirotoni/memo/models"
@@ -10,21 +10,25 @@ import (
 
 func (app *App) Links() {
 	// retrieve keys
-	var memos []*models.Memo
-	dms, err := app.repos.DailymemoRepo.Entries()
-	if err != nil {
-		log.Fatal("error")
-	}
-	for _, dm := range dms {
-		mm := app.repos.DailymemoRepo.MemosFromDailymemo(dm)
-		memos = append(memos, mm...)
+	idx := app.loadIndex()
+	memos := idx.Memos()
+
+	// only files that contain every term of a key can mention the memo
+	var candidates = make(map[*models.Memo][]string)
+	for _, v := range memos {
+		for _, key := range v.SearchKeys() {
+			candidates[v] = append(candidates[v], idx.Candidates(key)...)
+		}
 	}
 
 	// search links
 	var links map[string][]string = make(map[string][]string)
 	for _, m := range memos {
 		for _, v := range memos {
-			if strings.Contains(m.Content, v.SearchKey()) {
+			if !slices.Contains(candidates[v], m.Filepath) {
+				continue
+			}
+			if slices.ContainsFunc(v.SearchKeys(), func(key string) bool { return strings.Contains(m.Content, key) }) {
 				key := v.Link()
 				value := m.Link()
 				links[key] = append(links[key], value)
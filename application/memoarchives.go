@@ -2,68 +2,197 @@ package application
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
 	"log"
 	"math/rand"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hirotoni/memo/components"
+	"github.com/hirotoni/memo/markdown"
 	"github.com/hirotoni/memo/models"
+	"github.com/hirotoni/memo/review"
 )
 
 // SaveMemoArchives generates memo archives index file
 func (app *App) SaveMemoArchives() {
-	app.saveMemoArchives(false)
+	app.saveMemoArchives(false, time.Now())
 }
 
-func (app *App) saveMemoArchives(pickMemoArchive bool) *models.MemoArchive {
-	checkedMemoArchives := app.repos.MemoArchiveRepo.MemoArchivesFromIndexChecked()                           // TODO handle error
+// saveMemoArchives generates memo archives index file.
+// If pick is true, it picks memo archives for the daily memo of the date, and the caller records them in the history once the daily memo is saved.
+func (app *App) saveMemoArchives(pick bool, date time.Time) []*models.MemoArchive {
+	indexedMemoArchives := app.repos.MemoArchiveRepo.MemoArchivesFromIndex() // TODO handle error
+	checkedMemoArchives := filter(indexedMemoArchives, func(t *models.MemoArchive) bool { return t.Checked })
 	allMemoArchives := app.repos.MemoArchiveNodeRepo.MemoArchiveNodesFromMemoArchivesDir(checkedMemoArchives) // TODO handle error
 	if len(allMemoArchives) == 0 {
 		return nil
 	}
+	for _, tn := range allMemoArchives {
+		i := slices.IndexFunc(indexedMemoArchives, func(t *models.MemoArchive) bool { return tn.MemoArchive.LinkedAs(t.Destination) })
+		if tn.Kind == models.MEMOARCHIVENODEKIND_MEMO && i >= 0 {
+			tn.MemoArchive.Annotation = indexedMemoArchives[i].Annotation
+		}
+	}
 
-	var picked *models.MemoArchive
-	if pickMemoArchive {
-		picked = pickRandomMemoArchive(allMemoArchives)
+	var picked []*models.MemoArchive
+	if pick {
+		schedule, err := review.Load(app.Config.ReviewFile())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if schedule.Rename(canonicalDestinations(allMemoArchives)) {
+			if err := schedule.Save(app.Config.ReviewFile()); err != nil {
+				log.Fatal(err)
+			}
+		}
+		picked = pickDueMemoArchives(allMemoArchives, schedule, app.Config.MemoArchives.PicksPerDay(), app.Config.MemoArchives.Weight, date, rand.New(rand.NewSource(date.Unix())))
 	}
 
 	var buf = &bytes.Buffer{}
-	components.PrintMemoArchiveNodeHeadingStyle(buf, allMemoArchives)
+	components.PrintMemoArchiveNodeHeadingStyle(buf, allMemoArchives, app.gmw)
 
 	// write memo archives to index
-	f, err := os.Create(app.Config.MemoArchivesIndexFile())
+	b, err := os.ReadFile(app.Config.MemoArchivesIndexFile())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatal(err)
+	}
+	b, err = app.updateMemoArchivesIndex(b, buf.String())
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer f.Close()
-	masb := []byte(components.GenerateTemplateString(components.TemplateMemoArchivesIndex))
-	masb = app.gmw.InsertTextAtHeadingStart(masb, components.HEADING_NAME_MEMOARCHIVES_INDEX, buf.String())
-	f.Write(masb)
+	if err := os.WriteFile(app.Config.MemoArchivesIndexFile(), b, 0644); err != nil {
+		log.Fatal(err)
+	}
 
 	return picked
 }
 
-func pickRandomMemoArchive(allMemoArchives []*models.MemoArchiveNode) *models.MemoArchive {
-	notShown := filter(allMemoArchives, func(tn *models.MemoArchiveNode) bool {
-		return tn.Kind == models.MEMOARCHIVENODEKIND_MEMO && !tn.MemoArchive.Checked
+// updateMemoArchivesIndex replaces the generated block of the index with the memo archives, keeping the rest as written.
+// The block is the region between the markers if any, or else the section of the index heading, which is added if missing,
+// and the markers are written around the block then.
+func (app *App) updateMemoArchivesIndex(source []byte, memoArchives string) ([]byte, error) {
+	if len(bytes.TrimSpace(source)) == 0 {
+		source = []byte(components.GenerateTemplateString(components.TemplateMemoArchivesIndex))
+	}
+
+	doc := app.gmw.NewDocument(source)
+	err := doc.ReplaceRegion(components.MARKER_MEMOARCHIVES_INDEX_BEGIN, components.MARKER_MEMOARCHIVES_INDEX_END, memoArchives)
+	// an index without markers gets them, so that what is written around the generated block is kept from now on
+	wrapped := components.MARKER_MEMOARCHIVES_INDEX_BEGIN + "\n\n" + strings.Trim(memoArchives, "\n") + "\n\n" + components.MARKER_MEMOARCHIVES_INDEX_END
+	if errors.Is(err, markdown.ErrRegionNotFound) {
+		err = doc.ReplaceSection(components.HEADING_NAME_MEMOARCHIVES_INDEX, wrapped)
+	}
+	if errors.Is(err, markdown.ErrHeadingNotFound) {
+		b := bytes.TrimRight(source, "\n")
+		b = append(b, "\n\n"+components.HEADING_NAME_MEMOARCHIVES_INDEX.String()+"\n\n"+wrapped+"\n"...)
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes(), nil
+}
+
+// pickDueMemoArchives picks up to n memo archives due for review, no two from the same category.
+// The most overdue ones are picked first, preferring ones not shown yet, and among equals, a category is picked at random by its weight.
+// Categories weighing 0 are never picked. The picks are reproducible for the same source of randomness.
+func pickDueMemoArchives(allMemoArchives []*models.MemoArchiveNode, schedule *review.Schedule, n int, weight func(category string) float64, date time.Time, r *rand.Rand) []*models.MemoArchive {
+	due := filter(allMemoArchives, func(tn *models.MemoArchiveNode) bool {
+		return tn.Kind == models.MEMOARCHIVENODEKIND_MEMO && weight(tn.Category) > 0 && schedule.Overdue(tn.MemoArchive.Destination, date) >= 0
 	})
 
-	if len(notShown) == 0 {
-		// reset all memo archives
+	var picks []*models.MemoArchive
+	for len(picks) < n && len(due) > 0 {
+		mostOverdue := slices.MaxFunc(due, func(a, b *models.MemoArchiveNode) int {
+			return cmp.Compare(schedule.Overdue(a.MemoArchive.Destination, date), schedule.Overdue(b.MemoArchive.Destination, date))
+		})
+		candidates := filter(due, func(tn *models.MemoArchiveNode) bool {
+			return schedule.Overdue(tn.MemoArchive.Destination, date) == schedule.Overdue(mostOverdue.MemoArchive.Destination, date)
+		})
+		if notShown := filter(candidates, func(tn *models.MemoArchiveNode) bool { return !tn.MemoArchive.Checked }); len(notShown) > 0 {
+			candidates = notShown
+		}
+
+		picked := weightedPick(candidates, weight, r)
 		for _, v := range allMemoArchives {
-			v.MemoArchive.Checked = false
+			if v.MemoArchive.Destination == picked.MemoArchive.Destination {
+				v.MemoArchive.Checked = true
+			}
+		}
+		picks = append(picks, &picked.MemoArchive)
+		due = filter(due, func(tn *models.MemoArchiveNode) bool { return tn.Category != picked.Category })
+	}
+	return picks
+}
+
+// weightedPick picks a category of the memo archives at random by its weight, and then a memo archive in it at random
+func weightedPick(memoArchives []*models.MemoArchiveNode, weight func(category string) float64, r *rand.Rand) *models.MemoArchiveNode {
+	var categories []string
+	var total float64
+	for _, tn := range memoArchives {
+		if !slices.Contains(categories, tn.Category) {
+			categories = append(categories, tn.Category)
+			total += weight(tn.Category)
+		}
+	}
+
+	category := categories[len(categories)-1]
+	x := r.Float64() * total
+	for _, c := range categories {
+		if x < weight(c) {
+			category = c
+			break
 		}
-		notShown = allMemoArchives
+		x -= weight(c)
+	}
+
+	picked, _ := randomPick(filter(memoArchives, func(tn *models.MemoArchiveNode) bool { return tn.Category == category }), r)
+	return picked
+}
+
+// ReviewMemoArchive records how well the memo archive was remembered on the date, from 0 (forgotten) to 5 (perfect), and prints when to review it next
+func (app *App) ReviewMemoArchive(destination string, grade int, date time.Time) {
+	memoArchives := app.repos.MemoArchiveNodeRepo.MemoArchiveNodesFromMemoArchivesDir(nil)
+	canonical := canonicalDestinations(memoArchives)
+	if d, ok := canonical[destination]; ok {
+		destination = d
+	}
+	if !slices.ContainsFunc(memoArchives, func(tn *models.MemoArchiveNode) bool {
+		return tn.Kind == models.MEMOARCHIVENODEKIND_MEMO && tn.MemoArchive.Destination == destination
+	}) {
+		log.Fatalf("memo archive not found: %s", destination)
 	}
 
-	picked, _ := randomPick(notShown)
+	schedule, err := review.Load(app.Config.ReviewFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	schedule.Rename(canonical)
+	item, err := schedule.Review(destination, grade, date)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := schedule.Save(app.Config.ReviewFile()); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("next review of %s on %s\n", destination, item.Due)
+}
 
-	for _, v := range allMemoArchives {
-		if v.MemoArchive.Destination == picked.MemoArchive.Destination {
-			v.MemoArchive.Checked = true
+// canonicalDestinations returns the destinations of the memo archives keyed by the ones they were linked with before,
+// so that state keyed by old destinations, such as review schedules and the history, is carried over
+func canonicalDestinations(allMemoArchives []*models.MemoArchiveNode) map[string]string {
+	canonical := map[string]string{}
+	for _, tn := range allMemoArchives {
+		for _, alias := range tn.MemoArchive.Aliases {
+			canonical[alias] = tn.MemoArchive.Destination
 		}
 	}
-	return &picked.MemoArchive
+	return canonical
 }
 
 func filter[T any](ts []T, test func(T) bool) (ret []T) {
@@ -75,8 +204,8 @@ func filter[T any](ts []T, test func(T) bool) (ret []T) {
 	return
 }
 
-func randomPick[T any](s []T) (T, []T) {
-	i := rand.Intn(len(s))
+func randomPick[T any](s []T, r *rand.Rand) (T, []T) {
+	i := r.Intn(len(s))
 	picked := s[i]
 	return picked, append(s[:i], s[i+1:]...)
 }
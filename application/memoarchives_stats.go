@@ -0,0 +1,163 @@
+package application
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/hirotoni/memo/review"
+)
+
+// showingStats is how much of a category has been shown in daily memos
+type showingStats struct {
+	Category string
+	Items    int
+	Shown    int   // items shown at least once
+	Gaps     []int // days between showings of the same item
+}
+
+// Coverage returns the ratio of the items shown at least once
+func (s showingStats) Coverage() float64 {
+	if s.Items == 0 {
+		return 0
+	}
+	return float64(s.Shown) / float64(s.Items)
+}
+
+// AverageDays returns the average days between showings of the same item. ok is false if no item has been shown twice.
+func (s showingStats) AverageDays() (avg float64, ok bool) {
+	if len(s.Gaps) == 0 {
+		return 0, false
+	}
+	var sum int
+	for _, g := range s.Gaps {
+		sum += g
+	}
+	return float64(sum) / float64(len(s.Gaps)), true
+}
+
+// MemoArchivesStats prints coverage of memo archives shown in daily memos per category, and the memo archives never shown
+func (app *App) MemoArchivesStats() {
+	history, err := review.LoadHistory(app.Config.HistoryFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	allMemoArchives := app.repos.MemoArchiveNodeRepo.MemoArchiveNodesFromMemoArchivesDir(nil)
+	stats, total, neverShown := showingStatsOf(allMemoArchives, history)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "category\tshown\tcoverage\tavg days")
+	for _, s := range append(stats, total) {
+		avg := "-"
+		if days, ok := s.AverageDays(); ok {
+			avg = fmt.Sprintf("%.1f", days)
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%.0f%%\t%s\n", s.Category, s.Shown, s.Items, s.Coverage()*100, avg)
+	}
+	w.Flush()
+
+	if len(neverShown) > 0 {
+		fmt.Println()
+		fmt.Println("never shown:")
+		for _, ma := range neverShown {
+			fmt.Println(app.gmw.BuildList(markdown.BuildLink(ma.Text, ma.Destination)))
+		}
+	}
+}
+
+// MemoArchiveHistory prints the dates the memo archive was shown and the daily memos it was inserted into
+func (app *App) MemoArchiveHistory(destination string) {
+	history, err := review.LoadHistory(app.Config.HistoryFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	canonical := canonicalDestinations(app.repos.MemoArchiveNodeRepo.MemoArchiveNodesFromMemoArchivesDir(nil))
+	if d, ok := canonical[destination]; ok {
+		destination = d
+	}
+	showings := filter(canonicalShowings(history, canonical), func(s review.Showing) bool { return s.Destination == destination })
+	if len(showings) == 0 {
+		fmt.Printf("%s has never been shown\n", destination)
+		return
+	}
+	slices.SortStableFunc(showings, func(a, b review.Showing) int { return strings.Compare(a.Date, b.Date) })
+	// showings recorded with a destination linked before may be on the same day as ones with the current one
+	showings = slices.CompactFunc(showings, func(a, b review.Showing) bool { return a.Date == b.Date })
+	for _, s := range showings {
+		fmt.Printf("%s %s\n", s.Date, s.Dailymemo)
+	}
+}
+
+// canonicalShowings replaces the destinations of the showings with the canonical ones they are mapped to
+func canonicalShowings(history []review.Showing, canonical map[string]string) []review.Showing {
+	for i, s := range history {
+		if d, ok := canonical[s.Destination]; ok {
+			history[i].Destination = d
+		}
+	}
+	return history
+}
+
+// showingStatsOf returns the stats of memo archives per category in the order of the nodes, the stats of all of them,
+// and the memo archives never shown. Showings of memo archives no longer in the nodes are ignored,
+// and ones recorded with destinations the memo archives were linked with before count for them.
+func showingStatsOf(allMemoArchives []*models.MemoArchiveNode, history []review.Showing) ([]showingStats, showingStats, []*models.MemoArchive) {
+	dates := map[string][]string{}
+	for _, s := range canonicalShowings(slices.Clone(history), canonicalDestinations(allMemoArchives)) {
+		if !slices.Contains(dates[s.Destination], s.Date) {
+			dates[s.Destination] = append(dates[s.Destination], s.Date)
+		}
+	}
+
+	var stats []showingStats
+	var total = showingStats{Category: "total"}
+	var neverShown []*models.MemoArchive
+	for _, tn := range allMemoArchives {
+		if tn.Kind != models.MEMOARCHIVENODEKIND_MEMO {
+			continue
+		}
+		i := slices.IndexFunc(stats, func(s showingStats) bool { return s.Category == tn.Category })
+		if i < 0 {
+			stats = append(stats, showingStats{Category: tn.Category})
+			i = len(stats) - 1
+		}
+
+		ds := dates[tn.MemoArchive.Destination]
+		slices.Sort(ds) // the dates sort lexically
+		var gaps []int
+		for j := 1; j < len(ds); j++ {
+			gaps = append(gaps, daysBetween(ds[j-1], ds[j]))
+		}
+
+		for _, s := range []*showingStats{&stats[i], &total} {
+			s.Items++
+			if len(ds) > 0 {
+				s.Shown++
+			}
+			s.Gaps = append(s.Gaps, gaps...)
+		}
+		if len(ds) == 0 {
+			neverShown = append(neverShown, &tn.MemoArchive)
+		}
+	}
+	return stats, total, neverShown
+}
+
+// daysBetween returns the days from the date to the date in the layout of review.DATE_LAYOUT
+func daysBetween(from, to string) int {
+	f, err := time.Parse(review.DATE_LAYOUT, from)
+	if err != nil {
+		return 0
+	}
+	t, err := time.Parse(review.DATE_LAYOUT, to)
+	if err != nil {
+		return 0
+	}
+	return int(t.Sub(f).Hours() / 24)
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/hirotoni/memo/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickDueMemoArchives(t *testing.T) {
+	date := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	nodes := func(checked ...string) []*models.MemoArchiveNode {
+		var ret = []*models.MemoArchiveNode{{Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "go", Category: "go"}}
+		for _, d := range []string{"a.md", "b.md", "c.md"} {
+			category := "go/Go"
+			if d == "c.md" {
+				category = "sql/SQL"
+			}
+			ret = append(ret, &models.MemoArchiveNode{
+				Kind:        models.MEMOARCHIVENODEKIND_MEMO,
+				MemoArchive: models.MemoArchive{Text: d, Destination: d, Checked: slices.Contains(checked, d)},
+				Category:    category,
+			})
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name    string
+		items   map[string]*review.Item
+		checked []string
+		n       int
+		weights configs.MemoArchivesConfig
+		want    []string
+	}{
+		{
+			name:  "most overdue first",
+			items: map[string]*review.Item{"a.md": {Due: "2025-01-08"}, "b.md": {Due: "2025-01-05"}},
+			n:     1,
+			want:  []string{"b.md"},
+		},
+		{
+			name:    "not shown first among never reviewed",
+			checked: []string{"a.md", "b.md"},
+			n:       1,
+			want:    []string{"c.md"},
+		},
+		{
+			name:    "shown again when all are shown",
+			items:   map[string]*review.Item{"a.md": {Due: "2025-01-11"}, "b.md": {Due: "2025-01-11"}},
+			checked: []string{"c.md"},
+			n:       1,
+			want:    []string{"c.md"},
+		},
+		{
+			name:  "nothing due",
+			items: map[string]*review.Item{"a.md": {Due: "2025-01-11"}, "b.md": {Due: "2025-01-11"}, "c.md": {Due: "2025-01-12"}},
+			n:     1,
+			want:  nil,
+		},
+		{
+			name:  "no two from the same category",
+			items: map[string]*review.Item{"a.md": {Due: "2025-01-05"}},
+			n:     3,
+			want:  []string{"a.md", "c.md"},
+		},
+		{
+			name:    "category weighing 0 never picked",
+			n:       3,
+			weights: configs.MemoArchivesConfig{Weights: map[string]float64{"go": 0}},
+			want:    []string{"c.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			schedule := review.New()
+			for d, item := range tt.items {
+				schedule.Items[d] = item
+			}
+
+			var got []string
+			for _, ma := range pickDueMemoArchives(nodes(tt.checked...), schedule, tt.n, tt.weights.Weight, date, rand.New(rand.NewSource(1))) {
+				assert.True(ma.Checked)
+				got = append(got, ma.Destination)
+			}
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestPickDueMemoArchives_weights(t *testing.T) {
+	date := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	weights := configs.MemoArchivesConfig{Weights: map[string]float64{"go": 3}}
+
+	var picked = map[string]int{}
+	for seed := range 1000 {
+		all := []*models.MemoArchiveNode{
+			{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "a.md"}, Category: "go/Go"},
+			{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "b.md"}, Category: "go/Go"},
+			{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "c.md"}, Category: "sql/SQL"},
+		}
+		for _, ma := range pickDueMemoArchives(all, review.New(), 1, weights.Weight, date, rand.New(rand.NewSource(int64(seed)))) {
+			picked[ma.Destination]++
+		}
+	}
+	// go is picked 3 times as often as sql, and the memo archives in it evenly
+	assert.InDelta(t, 750, picked["a.md"]+picked["b.md"], 60)
+	assert.InDelta(t, 375, picked["a.md"], 60)
+}
+
+func TestShowingStatsOf(t *testing.T) {
+	assert := assert.New(t)
+	all := []*models.MemoArchiveNode{
+		{Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "go", Category: "go"},
+		{Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "Go", Category: "go/Go"},
+		{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "a.md"}, Category: "go/Go"},
+		{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "b.md"}, Category: "go/Go"},
+		{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "c.md"}, Category: "sql/SQL"},
+	}
+	history := []review.Showing{
+		{Date: "2025-01-01", Destination: "a.md"},
+		{Date: "2025-01-05", Destination: "a.md"},
+		{Date: "2025-01-05", Destination: "a.md"}, // generated twice on the same day
+		{Date: "2025-01-03", Destination: "c.md"},
+		{Date: "2025-01-13", Destination: "a.md"},
+		{Date: "2025-01-04", Destination: "removed.md"},
+	}
+
+	stats, total, neverShown := showingStatsOf(all, history)
+
+	assert.Equal([]showingStats{
+		{Category: "go/Go", Items: 2, Shown: 1, Gaps: []int{4, 8}},
+		{Category: "sql/SQL", Items: 1, Shown: 1},
+	}, stats)
+	assert.Equal(showingStats{Category: "total", Items: 3, Shown: 2, Gaps: []int{4, 8}}, total)
+	assert.InDelta(2.0/3, total.Coverage(), 1e-9)
+	avg, ok := total.AverageDays()
+	assert.True(ok)
+	assert.Equal(6.0, avg)
+	_, ok = stats[1].AverageDays()
+	assert.False(ok)
+	assert.Equal([]*models.MemoArchive{&all[3].MemoArchive}, neverShown)
+}
+
+func TestUpdateMemoArchivesIndex(t *testing.T) {
+	app := NewApp()
+	app.WithCustomConfig(*configs.NewTomlConfig("testdata", 10, markdown.NewGoldmarkWrapper()))
+	generated := "## go\n\n- [ ] [generics](../memoarchives/go/notes.md#generics)\n"
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "new index",
+			source: "",
+			want:   "# Memo Archives Index\n\n<!-- memo:archives:begin -->\n\n" + generated + "\n<!-- memo:archives:end -->\n",
+		},
+		{
+			name:   "section of the heading",
+			source: "notes to keep\n\n# Memo Archives Index\n\n## old\n\n- [ ] [old](old.md)\n\n# my picks\n\n- channels\n",
+			want:   "notes to keep\n\n# Memo Archives Index\n\n<!-- memo:archives:begin -->\n\n" + generated + "\n<!-- memo:archives:end -->\n\n# my picks\n\n- channels\n",
+		},
+		{
+			name:   "region between markers",
+			source: "# Memo Archives Index\n\nnotes to keep\n\n<!-- memo:archives:begin -->\n- [ ] [old](old.md)\n<!-- memo:archives:end -->\n\n## my picks\n",
+			want:   "# Memo Archives Index\n\nnotes to keep\n\n<!-- memo:archives:begin -->\n\n" + generated + "\n<!-- memo:archives:end -->\n\n## my picks\n",
+		},
+		{
+			name:   "heading removed",
+			source: "# my index\n\nnotes to keep\n",
+			want:   "# my index\n\nnotes to keep\n\n# Memo Archives Index\n\n<!-- memo:archives:begin -->\n\n" + generated + "\n<!-- memo:archives:end -->\n",
+		},
+		{
+			name:   "notes added around the markers later",
+			source: "# Memo Archives Index\n\nnotes to keep\n\n<!-- memo:archives:begin -->\n\n## old\n\n<!-- memo:archives:end -->\n\n## my picks\n",
+			want:   "# Memo Archives Index\n\nnotes to keep\n\n<!-- memo:archives:begin -->\n\n" + generated + "\n<!-- memo:archives:end -->\n\n## my picks\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := app.updateMemoArchivesIndex([]byte(tt.source), generated)
+			assert.NoError(err)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
+
+func TestShowingStatsOf_aliases(t *testing.T) {
+	assert := assert.New(t)
+	all := []*models.MemoArchiveNode{
+		{Kind: models.MEMOARCHIVENODEKIND_MEMO, MemoArchive: models.MemoArchive{Destination: "top%20level.md#intro", Aliases: []string{"top level.md#intro"}}, Category: "Top"},
+	}
+	history := []review.Showing{
+		{Date: "2025-01-01", Destination: "top level.md#intro"},
+		{Date: "2025-01-03", Destination: "top%20level.md#intro"},
+	}
+
+	_, total, neverShown := showingStatsOf(all, history)
+
+	assert.Equal(showingStats{Category: "total", Items: 1, Shown: 1, Gaps: []int{2}}, total)
+	assert.Empty(neverShown)
+	assert.Equal("top level.md#intro", history[0].Destination)
+}
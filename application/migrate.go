@@ -0,0 +1,222 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+)
+
+// Migrate renames daily memos to the filename layout and locale, and rewrites links pointing at the old names.
+// An empty locale keeps the current one. The changes are staged first, and undone if any of them fails.
+func (app *App) Migrate(toLayout, toLocale string, dryRun bool) {
+	if toLocale == "" {
+		toLocale = app.Config.DateLayout().Locale
+	}
+	to, err := configs.NewDateLayout(toLayout, toLocale)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	renames, err := app.migrationRenames(to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	prefix := ""
+	if dryRun {
+		prefix = "(dry-run) "
+	}
+
+	// links are found at the paths before renaming
+	m := migration{rewrites: map[string][]byte{}, originals: map[string][]byte{}, renames: map[string]string{}}
+	for _, dir := range []string{app.Config.DailymemoDir(), app.Config.MemoArchivesDir()} {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != ".md" {
+				return nil
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rewritten, n := rewriteLinks(app.gmw, b, filepath.Dir(path), app.Config.DailymemoDir(), renames)
+			if n > 0 {
+				m.rewrites[path] = rewritten
+				m.originals[path] = b
+				fmt.Printf("%srewrote %d links in %s\n", prefix, n, app.relpath(path))
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatal(err)
+		}
+	}
+	for _, oldName := range slices.Sorted(maps.Keys(renames)) {
+		m.renames[filepath.Join(app.Config.DailymemoDir(), oldName)] = filepath.Join(app.Config.DailymemoDir(), renames[oldName])
+		fmt.Printf("%srenamed %s to %s\n", prefix, oldName, renames[oldName])
+	}
+
+	if len(renames) == 0 && len(m.rewrites) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+	if dryRun {
+		return
+	}
+
+	rollback, err := m.apply()
+	if err != nil {
+		log.Fatal(err)
+	}
+	values := map[string]string{"filenamelayout": to.Layout}
+	if to.Locale != app.Config.DateLayout().Locale {
+		values["weekdaylocale"] = to.Locale
+	}
+	if err := configs.UpdateTomlConfig(values); err != nil {
+		rollback()
+		log.Fatal(err)
+	}
+	app.Config.FilenameLayout = to.Layout
+	app.Config.WeekdayLocale = to.Locale
+	app.WeeklyReport()
+}
+
+// migration is the changes to files of a migration
+type migration struct {
+	rewrites  map[string][]byte // contents with links rewritten, keyed by path before renaming
+	originals map[string][]byte // contents before rewriting, keyed by path before renaming
+	renames   map[string]string // new paths keyed by old paths
+}
+
+// apply writes the rewritten contents to files beside the originals, then moves them over the originals and renames the files.
+// If any of them fails, the changes made so far are undone. It returns a function that undoes all the changes.
+func (m migration) apply() (func(), error) {
+	var undo []func() error
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := undo[i](); err != nil {
+				log.Printf("failed to roll back: %v", err)
+			}
+		}
+	}
+
+	staged := map[string]string{}
+	defer func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+	}()
+	for _, path := range slices.Sorted(maps.Keys(m.rewrites)) {
+		tmp := path + ".migrating"
+		if err := os.WriteFile(tmp, m.rewrites[path], 0644); err != nil {
+			return nil, err
+		}
+		staged[path] = tmp
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(staged)) {
+		if err := os.Rename(staged[path], path); err != nil {
+			rollback()
+			return nil, err
+		}
+		delete(staged, path)
+		original := m.originals[path]
+		undo = append(undo, func() error { return os.WriteFile(path, original, 0644) })
+	}
+	for _, oldPath := range slices.Sorted(maps.Keys(m.renames)) {
+		newPath := m.renames[oldPath]
+		if err := os.Rename(oldPath, newPath); err != nil {
+			rollback()
+			return nil, err
+		}
+		undo = append(undo, func() error { return os.Rename(newPath, oldPath) })
+	}
+	return rollback, nil
+}
+
+// migrationRenames returns new basenames of daily memos keyed by the current ones.
+// It returns an error if a new name is taken by another file.
+func (app *App) migrationRenames(to configs.DateLayout) (map[string]string, error) {
+	dms, err := app.repos.DailymemoRepo.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	renames := map[string]string{}
+	taken := map[string]string{}
+	for _, dm := range dms {
+		newName := fmt.Sprintf(FILENAME_FORMAT, to.Format(*dm.Date))
+		if newName == dm.BaseName {
+			continue
+		}
+		if other, ok := taken[newName]; ok {
+			return nil, fmt.Errorf("both %s and %s would be renamed to %s", other, dm.BaseName, newName)
+		}
+		if _, err := os.Stat(filepath.Join(app.Config.DailymemoDir(), newName)); err == nil {
+			return nil, fmt.Errorf("%s cannot be renamed to %s: the file already exists", dm.BaseName, newName)
+		}
+		taken[newName] = dm.BaseName
+		renames[dm.BaseName] = newName
+	}
+	return renames, nil
+}
+
+// rewriteLinks rewrites destinations of links in the source which point at renamed daily memos, and returns the result and the number of rewritten links.
+// dir is the directory of the source, against which relative destinations are resolved.
+func rewriteLinks(gmw *markdown.GoldmarkWrapper, source []byte, dir, dailymemoDir string, renames map[string]string) ([]byte, int) {
+	var count int
+	var buf bytes.Buffer
+	last := 0
+	for _, span := range gmw.LinkDestinations(source) {
+		dest := string(source[span.Start:span.Stop])
+		if strings.Contains(dest, "://") {
+			continue
+		}
+		destPath, fragment, _ := strings.Cut(dest, "#")
+		unescaped, err := url.PathUnescape(destPath)
+		if err != nil {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(unescaped))
+		newName, ok := renames[filepath.Base(target)]
+		if !ok || filepath.Clean(filepath.Dir(target)) != filepath.Clean(dailymemoDir) {
+			continue
+		}
+
+		angled := span.Start > 0 && source[span.Start-1] == '<'
+		if unescaped != destPath || !angled && strings.ContainsAny(newName, " \t") {
+			newName = url.PathEscape(newName)
+		}
+		newDest := destPath[:strings.LastIndex(destPath, "/")+1] + newName
+		if strings.Contains(dest, "#") {
+			newDest += "#" + fragment
+		}
+
+		buf.Write(source[last:span.Start])
+		buf.WriteString(newDest)
+		last = span.Stop
+		count++
+	}
+	buf.Write(source[last:])
+	return buf.Bytes(), count
+}
+
+// relpath returns the path relative to the base dir for messages
+func (app *App) relpath(path string) string {
+	rel, err := filepath.Rel(app.Config.BaseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
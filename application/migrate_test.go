@@ -0,0 +1,118 @@
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hirotoni/memo/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteLinks(t *testing.T) {
+	renames := map[string]string{
+		"2025-01-01-Wed.md": "2025-01-01.md",
+		"2025-01-02-Thu.md": "2025-01-02.md",
+		"2025年01月03日(金).md": "2025-01-03.md",
+		"2025-01-04-Sat.md": "2025 01 04.md",
+	}
+	tests := []struct {
+		name     string
+		dir      string
+		source   string
+		expected string
+		count    int
+	}{
+		{
+			name:     "same directory with fragment",
+			dir:      "base/dailymemo",
+			source:   "- [2025-01-01-Wed](2025-01-01-Wed.md#todos)\n1. [memo](2025-01-02-Thu.md)\n",
+			expected: "- [2025-01-01-Wed](2025-01-01.md#todos)\n1. [memo](2025-01-02.md)\n",
+			count:    2,
+		},
+		{
+			name:     "other directory",
+			dir:      "base/memoarchives/go",
+			source:   "[memo](../../dailymemo/2025-01-01-Wed.md) [angle](<../../dailymemo/2025-01-02-Thu.md>)",
+			expected: "[memo](../../dailymemo/2025-01-01.md) [angle](<../../dailymemo/2025-01-02.md>)",
+			count:    2,
+		},
+		{
+			name:     "parentheses in the name",
+			dir:      "base/dailymemo",
+			source:   "[memo](2025年01月03日(金).md#todos) and [escaped](2025%E5%B9%B401%E6%9C%8803%E6%97%A5%28%E9%87%91%29.md)\n",
+			expected: "[memo](2025-01-03.md#todos) and [escaped](2025-01-03.md)\n",
+			count:    2,
+		},
+		{
+			name:     "space in the new name",
+			dir:      "base/dailymemo",
+			source:   "[memo](2025-01-04-Sat.md) [angle](<2025-01-04-Sat.md>)",
+			expected: "[memo](2025%2001%2004.md) [angle](<2025 01 04.md>)",
+			count:    2,
+		},
+		{
+			name:     "code and reference links",
+			dir:      "base/dailymemo",
+			source:   "`[a](2025-01-01-Wed.md)` [b][1] [c][1]\n\n[1]: 2025-01-02-Thu.md\n",
+			expected: "`[a](2025-01-01-Wed.md)` [b][1] [c][1]\n\n[1]: 2025-01-02.md\n",
+			count:    1,
+		},
+		{
+			name:     "not daily memos",
+			dir:      "base/dailymemo",
+			source:   "[a](../memoarchives/2025-01-01-Wed.md) [b](https://example.com/2025-01-01-Wed.md) [c](2025-01-03-Fri.md)",
+			expected: "[a](../memoarchives/2025-01-01-Wed.md) [b](https://example.com/2025-01-01-Wed.md) [c](2025-01-03-Fri.md)",
+			count:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, count := rewriteLinks(markdown.NewGoldmarkWrapper(), []byte(tt.source), tt.dir, "base/dailymemo", renames)
+			assert.Equal(t, tt.expected, string(result))
+			assert.Equal(t, tt.count, count)
+		})
+	}
+}
+
+func TestMigration_apply(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	assert.NoError(os.WriteFile(a, []byte("[b](b.md)\n"), 0644))
+	assert.NoError(os.WriteFile(b, []byte("b\n"), 0644))
+
+	m := migration{
+		rewrites:  map[string][]byte{a: []byte("[b](c.md)\n")},
+		originals: map[string][]byte{a: []byte("[b](b.md)\n")},
+		renames:   map[string]string{a: filepath.Join(dir, "a2.md"), b: filepath.Join(dir, "missing", "c.md")},
+	}
+	_, err := m.apply()
+	assert.Error(err)
+
+	// renaming b failed, so a is back as it was
+	entries, err := os.ReadDir(dir)
+	assert.NoError(err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal([]string{"a.md", "b.md"}, names)
+	got, err := os.ReadFile(a)
+	assert.NoError(err)
+	assert.Equal("[b](b.md)\n", string(got))
+
+	m.renames = map[string]string{b: filepath.Join(dir, "c.md")}
+	rollback, err := m.apply()
+	assert.NoError(err)
+	got, err = os.ReadFile(a)
+	assert.NoError(err)
+	assert.Equal("[b](c.md)\n", string(got))
+	assert.FileExists(filepath.Join(dir, "c.md"))
+
+	rollback()
+	assert.FileExists(b)
+	got, err = os.ReadFile(a)
+	assert.NoError(err)
+	assert.Equal("[b](b.md)\n", string(got))
+}
@@ -0,0 +1,121 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hirotoni/memo/index"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+)
+
+type SearchOptions struct {
+	Regex      bool   // treat the query as a regular expression
+	IgnoreCase bool   // match case-insensitively
+	Heading    string // only search lines in the sections of the heading selector. e.g. `memos > deploy`
+}
+
+// Search searches daily memos and memo archives, and prints each hit with its file, heading path and line number
+func (app *App) Search(query string, opts SearchOptions) {
+	matcher, err := newSearchMatcher(query, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var heading markdown.HeadingSelector
+	if opts.Heading != "" {
+		heading, err = markdown.ParseSelector(opts.Heading)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, relpath := range app.searchCandidates(query, opts) {
+		b, err := os.ReadFile(filepath.Join(app.Config.BaseDir, relpath))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, hit := range app.searchSource(relpath, b, matcher, heading) {
+			fmt.Printf("%s:%d: %s: %s\n", hit.Filepath, hit.Line, hit.Location(), hit.Text)
+		}
+	}
+}
+
+// searchCandidates returns files that may contain the query, relative to the base dir.
+// Plain queries are looked up in the index and the files are ranked by relevance.
+// Regular expressions cannot be looked up, so every indexed file is a candidate.
+func (app *App) searchCandidates(query string, opts SearchOptions) []string {
+	idx := app.loadIndex()
+	if opts.Regex || len(index.Tokenize(query)) == 0 {
+		return idx.Paths()
+	}
+
+	var candidates []string
+	for _, r := range idx.Search(query) {
+		candidates = append(candidates, r.Path)
+	}
+	return candidates
+}
+
+// searchTargets returns daily memo files and memo archive files
+func (app *App) searchTargets() []string {
+	targets, err := app.repos.DailymemoRepo.Filepaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mas, err := app.repos.MemoArchiveNodeRepo.MemoArchiveFiles()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return append(targets, mas...)
+}
+
+// searchSource returns lines of the source that match, limited to lines in the sections of the heading unless it is nil
+func (app *App) searchSource(fpath string, source []byte, matcher func(string) bool, heading markdown.HeadingSelector) []*models.SearchHit {
+	var hits []*models.SearchHit
+	paths := app.gmw.HeadingPathsByLine(source)
+	var inSections []bool
+	if heading != nil {
+		inSections = app.gmw.LinesInSections(source, heading)
+	}
+	for i, line := range bytes.Split(source, []byte("\n")) {
+		if inSections != nil && !inSections[i] {
+			continue
+		}
+		if !matcher(string(line)) {
+			continue
+		}
+		hits = append(hits, &models.SearchHit{
+			Filepath:    fpath,
+			HeadingPath: paths[i],
+			Line:        i + 1,
+			Text:        strings.TrimSpace(string(line)),
+		})
+	}
+	return hits
+}
+
+func newSearchMatcher(query string, opts SearchOptions) (func(string) bool, error) {
+	if query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if opts.Regex {
+		if opts.IgnoreCase {
+			query = "(?i)" + query
+		}
+		reg, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		return reg.MatchString, nil
+	}
+	if opts.IgnoreCase {
+		query = strings.ToLower(query)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), query) }, nil
+	}
+	return func(s string) bool { return strings.Contains(s, query) }, nil
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchSource(t *testing.T) {
+	app := NewApp()
+	app.WithCustomConfig(
+		*configs.NewTomlConfig(
+			"testdata",
+			10,
+			markdown.NewGoldmarkWrapper(),
+		),
+	)
+
+	source := []byte(`# daily memo
+
+## todos
+
+- [ ] deploy api
+
+## memos
+
+### Deploy notes
+
+deploy went well
+`)
+
+	tests := []struct {
+		name  string
+		query string
+		opts  SearchOptions
+		want  []*models.SearchHit
+	}{
+		{
+			name:  "plain",
+			query: "deploy",
+			want: []*models.SearchHit{
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "todos"}, Line: 5, Text: "- [ ] deploy api"},
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "memos", "Deploy notes"}, Line: 11, Text: "deploy went well"},
+			},
+		},
+		{
+			name:  "ignore case",
+			query: "DEPLOY NOTES",
+			opts:  SearchOptions{IgnoreCase: true},
+			want: []*models.SearchHit{
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "memos", "Deploy notes"}, Line: 9, Text: "### Deploy notes"},
+			},
+		},
+		{
+			name:  "regex",
+			query: `^- \[ \]`,
+			opts:  SearchOptions{Regex: true},
+			want: []*models.SearchHit{
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "todos"}, Line: 5, Text: "- [ ] deploy api"},
+			},
+		},
+		{
+			name:  "heading",
+			query: "deploy",
+			opts:  SearchOptions{Heading: "memos"},
+			want: []*models.SearchHit{
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "memos", "Deploy notes"}, Line: 11, Text: "deploy went well"},
+			},
+		},
+		{
+			name:  "heading path",
+			query: "deploy",
+			opts:  SearchOptions{Heading: "daily memo > todos"},
+			want: []*models.SearchHit{
+				{Filepath: "memo.md", HeadingPath: []string{"daily memo", "todos"}, Line: 5, Text: "- [ ] deploy api"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			matcher, err := newSearchMatcher(tt.query, tt.opts)
+			assert.NoError(err)
+			var heading markdown.HeadingSelector
+			if tt.opts.Heading != "" {
+				heading, err = markdown.ParseSelector(tt.opts.Heading)
+				assert.NoError(err)
+			}
+			assert.Equal(tt.want, app.searchSource("memo.md", source, matcher, heading))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hirotoni/memo/components"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+)
+
+// templateData is the data available in daily memo templates, e.g. {{.Date}}
+type templateData struct {
+	Date         string // e.g. 2025-01-06
+	Weekday      string // e.g. Mon, or 月 in Japanese
+	ISOWeek      string // e.g. 2025-W02
+	PrevMemoLink string // link to the latest memo before the date, or empty if not found
+	NextMemoLink string // link to the memo of the next day
+}
+
+// executeTemplate fills in the variables of the daily memo template as of the date.
+// It returns an error if the template is not valid, such as one with literal braces, and callers use it as is then.
+func (app *App) executeTemplate(t *models.Dailymemo, date time.Time) ([]byte, error) {
+	tmpl, err := template.New(t.BaseName).Option("missingkey=error").Parse(string(t.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, app.templateData(date)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (app *App) templateData(date time.Time) templateData {
+	layout := app.Config.DateLayout()
+	year, week := date.ISOWeek()
+
+	data := templateData{
+		Date:    date.Format(SHORT_LAYOUT),
+		Weekday: layout.Weekday(date),
+		ISOWeek: fmt.Sprintf("%d-W%02d", year, week),
+	}
+
+	if prev := app.previousMemo(date); prev != nil {
+		data.PrevMemoLink = markdown.BuildLink(strings.TrimSuffix(prev.BaseName, ".md"), prev.BaseName)
+	}
+	next := layout.Format(date.AddDate(0, 0, 1))
+	data.NextMemoLink = markdown.BuildLink(next, fmt.Sprintf(FILENAME_FORMAT, next))
+
+	return data
+}
+
+// UpgradeTemplates inserts headings of the sections missing in the daily memo templates on disk, and prints the changes
+func (app *App) UpgradeTemplates(dryRun bool) {
+	fpaths, err := app.repos.DailymemoRepo.TemplateFilepaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	headings := components.NewTemplateDailymemo(app.Config.DailymemoSections()).Headings
+	var upgraded int
+	for _, fpath := range fpaths {
+		b, err := os.ReadFile(fpath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		upgradedContent := app.gmw.InsertMissingHeadings(b, headings)
+
+		relpath := app.relpath(fpath)
+		diff := components.UnifiedDiff(relpath, relpath, b, upgradedContent)
+		if diff == "" {
+			continue
+		}
+		fmt.Print(diff)
+		upgraded++
+
+		if dryRun {
+			continue
+		}
+		if err := os.WriteFile(fpath, upgradedContent, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if upgraded == 0 {
+		fmt.Println("templates are up to date")
+	}
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	app := NewApp()
+	app.WithCustomConfig(
+		*configs.NewTomlConfig(
+			"testdata",
+			10,
+			markdown.NewGoldmarkWrapper(),
+		),
+	)
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "variables",
+			date:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local),
+			template: "# daily memo\n\n{{.Date}} ({{.Weekday}}) {{.ISOWeek}}\n\n{{.PrevMemoLink}} | {{.NextMemoLink}}\n",
+			want:     "# daily memo\n\n2025-01-02 (Thu) 2025-W01\n\n[2025-01-01-Wed](2025-01-01-Wed.md) | [2025-01-03-Fri](2025-01-03-Fri.md)\n",
+		},
+		{
+			name:     "no previous memo",
+			date:     time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local),
+			template: "{{if .PrevMemoLink}}{{.PrevMemoLink}}{{else}}first memo{{end}}",
+			want:     "first memo",
+		},
+		{
+			name:     "unknown variable",
+			date:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local),
+			template: "{{.Tomorrow}}",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.executeTemplate(&models.Dailymemo{BaseName: "template.md", Content: []byte(tt.template)}, tt.date)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -35,17 +36,27 @@ func weekSpliter(date time.Time) string {
 // buildWeeklyReport builds weekly report
 func (app *App) buildWeeklyReport() string {
 	var sb strings.Builder
-	var curWeekNum int
+	var curYearNum, curWeekNum int
 
-	dms, _ := app.repos.DailymemoRepo.Entries()
+	memosSection, ok := app.Config.MemosSection()
+	if !ok {
+		return ""
+	}
+
+	dms, _ := app.repos.DailymemoRepo.Entries() // sorted by date
 	for _, dm := range dms {
-		if curWeekNum != dm.WeekNum() {
+		if curYearNum != dm.YearNum() || curWeekNum != dm.WeekNum() {
 			sb.WriteString(weekSpliter(*dm.Date))
-			curWeekNum = dm.WeekNum()
+			curYearNum, curWeekNum = dm.YearNum(), dm.WeekNum()
 		}
 
 		sb.WriteString(markdown.BuildHeading(3, dm.BaseName+"\n\n"))
-		_, hangingNodes := app.gmw.FindHeadingAndGetHangingNodes(dm.Content, components.HEADING_NAME_MEMOS)
+		doc := app.gmw.NewDocument(dm.Content)
+		_, hangingNodes, err := doc.HangingNodes(memosSection.MarkdownHeading())
+		if errors.Is(err, markdown.ErrAmbiguousHeading) {
+			log.Printf("%v in %s", err, dm.BaseName)
+		}
+		slugs := doc.HeadingSlugs(app.Config.Slugger())
 
 		var order = 0
 		for _, node := range hangingNodes {
@@ -58,8 +69,7 @@ func (app *App) buildWeeklyReport() string {
 				order++
 
 				title := markdown.BuildHeading(n.Level-2, string(node.Text(dm.Content)))
-				tag := markdown.Text2tag(string(node.Text(dm.Content)))
-				s := markdown.BuildOrderedList(order, markdown.BuildLink(title, relpath+"#"+tag)) + "\n"
+				s := app.gmw.BuildOrderedList(order, markdown.BuildLink(title, relpath+"#"+slugs[node])) + "\n"
 				sb.WriteString(s)
 			}
 		}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "same",
+			a:    "# a\n",
+			b:    "# a\n",
+			want: "",
+		},
+		{
+			name: "insert",
+			a:    "# daily memo\n\n## todos\n\n## memos\n",
+			b:    "# daily memo\n\n## todos\n\n## wanttodos\n\n## memos\n",
+			want: `--- a
++++ b
+@@ -2,4 +2,6 @@
+ 
+ ## todos
+ 
++## wanttodos
++
+ ## memos
+`,
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "# a\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+# a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnifiedDiff("a", "b", []byte(tt.a), []byte(tt.b)))
+		})
+	}
+}
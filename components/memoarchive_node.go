@@ -8,29 +8,39 @@ import (
 	"github.com/hirotoni/memo/models"
 )
 
-func PrintMemoArchiveNode(b *bytes.Buffer, tn *models.MemoArchiveNode) {
+// MAX_HEADING_LEVEL is the deepest heading in markdown, which nodes deeper than it share
+const MAX_HEADING_LEVEL = 6
+
+// PrintMemoArchiveNode writes the node as a list item with the list markers of the renderer options of the wrapper
+func PrintMemoArchiveNode(b *bytes.Buffer, tn *models.MemoArchiveNode, gmw *markdown.GoldmarkWrapper) {
 	var out string
 	switch tn.Kind {
 	case models.MEMOARCHIVENODEKIND_DIR:
-		out = strings.Repeat("  ", tn.Depth) + markdown.BuildList(tn.Text)
+		out = strings.Repeat("  ", tn.Depth) + gmw.BuildList(tn.Text)
 	case models.MEMOARCHIVENODEKIND_TITLE:
-		out = strings.Repeat("  ", tn.Depth) + markdown.BuildList(tn.Text)
+		out = strings.Repeat("  ", tn.Depth) + gmw.BuildList(tn.Text)
 	case models.MEMOARCHIVENODEKIND_MEMO:
-		out = strings.Repeat("  ", tn.Depth) + markdown.BuildCheckbox(markdown.BuildLink(tn.MemoArchive.Text, tn.MemoArchive.Destination), tn.MemoArchive.Checked)
+		out = strings.Repeat("  ", tn.Depth) + gmw.BuildCheckbox(markdown.BuildLink(tn.MemoArchive.Text, tn.MemoArchive.Destination), tn.MemoArchive.Checked)
 	}
 	b.WriteString(out + "\n")
 }
 
-func PrintMemoArchiveNodeHeadingStyle(b *bytes.Buffer, tns []*models.MemoArchiveNode) {
+// PrintMemoArchiveNodeHeadingStyle writes the directories and titles as headings and the memo archives as task list items under them,
+// with the list markers of the renderer options of the wrapper
+func PrintMemoArchiveNodeHeadingStyle(b *bytes.Buffer, tns []*models.MemoArchiveNode, gmw *markdown.GoldmarkWrapper) {
 	var out string
 	for i, tn := range tns {
 		switch tn.Kind {
 		case models.MEMOARCHIVENODEKIND_DIR:
-			out = strings.Repeat("#", tn.Depth+2) + " " + tn.Text + "\n"
+			out = strings.Repeat("#", min(tn.Depth+2, MAX_HEADING_LEVEL)) + " " + tn.Text + "\n"
 		case models.MEMOARCHIVENODEKIND_TITLE:
-			out = strings.Repeat("#", tn.Depth+2) + " " + tn.Text + "\n"
+			out = strings.Repeat("#", min(tn.Depth+2, MAX_HEADING_LEVEL)) + " " + tn.Text + "\n"
 		case models.MEMOARCHIVENODEKIND_MEMO:
-			out = markdown.BuildCheckbox(markdown.BuildLink(tn.MemoArchive.Text, tn.MemoArchive.Destination), tn.MemoArchive.Checked)
+			out = gmw.BuildCheckbox(markdown.BuildLink(tn.MemoArchive.Text, tn.MemoArchive.Destination), tn.MemoArchive.Checked)
+			if tn.MemoArchive.Annotation != "" {
+				// continuation lines are indented to stay in the item
+				out += " " + strings.ReplaceAll(tn.MemoArchive.Annotation, "\n", "\n  ")
+			}
 			if i < len(tns)-1 && tns[i+1].Kind != models.MEMOARCHIVENODEKIND_MEMO {
 				out += "\n"
 			}
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/hirotoni/memo/markdown"
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
 	"github.com/hirotoni/memo/models"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestPrintMemoArchiveNode(t *testing.T) {
 	type args struct {
-		b  *bytes.Buffer
-		tn *models.MemoArchiveNode
+		b   *bytes.Buffer
+		tn  *models.MemoArchiveNode
+		gmw *markdown.GoldmarkWrapper
 	}
 	tests := []struct {
 		name string
@@ -90,7 +93,11 @@ func TestPrintMemoArchiveNode(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert := assert.New(t)
-			PrintMemoArchiveNode(tt.args.b, tt.args.tn)
+			gmw := tt.args.gmw
+			if gmw == nil {
+				gmw = markdown.NewGoldmarkWrapper()
+			}
+			PrintMemoArchiveNode(tt.args.b, tt.args.tn, gmw)
 			assert.Equal(tt.want, tt.args.b.String())
 		})
 	}
@@ -100,6 +107,7 @@ func TestPrintMemoArchiveNodeHeadingStyleSlice(t *testing.T) {
 	type args struct {
 		b   *bytes.Buffer
 		tns []*models.MemoArchiveNode
+		gmw *markdown.GoldmarkWrapper
 	}
 	tests := []struct {
 		name string
@@ -182,11 +190,68 @@ func TestPrintMemoArchiveNodeHeadingStyleSlice(t *testing.T) {
 			},
 			want: "- [x] [text](destination)\n",
 		},
+		{
+			name: "TITLE deeper than headings",
+			args: args{
+				b: &bytes.Buffer{},
+				tns: []*models.MemoArchiveNode{
+					{
+						Kind:  models.MEMOARCHIVENODEKIND_TITLE,
+						Depth: 5,
+						Text:  "text",
+					},
+				},
+			},
+			want: "###### text\n\n",
+		},
+		{
+			name: "MEMOARCHIVE annotated",
+			args: args{
+				b: &bytes.Buffer{},
+				tns: []*models.MemoArchiveNode{
+					{
+						Kind:  models.MEMOARCHIVENODEKIND_MEMO,
+						Depth: 1,
+						Text:  "text",
+						MemoArchive: models.MemoArchive{
+							Text:        "text",
+							Destination: "destination",
+							Checked:     true,
+							Annotation:  "tricky\nsee the spec",
+						},
+					},
+				},
+			},
+			want: "- [x] [text](destination) tricky\n  see the spec\n",
+		},
+		{
+			name: "MEMOARCHIVE with bullet marker",
+			args: args{
+				b: &bytes.Buffer{},
+				tns: []*models.MemoArchiveNode{
+					{
+						Kind:  models.MEMOARCHIVENODEKIND_MEMO,
+						Depth: 1,
+						Text:  "text",
+						MemoArchive: models.MemoArchive{
+							Text:        "text",
+							Destination: "destination",
+						},
+					},
+				},
+				gmw: markdown.NewGoldmarkWrapper(markdown.WithRendererOptions(myrenderer.WithBulletMarker('*'))),
+			},
+			want: "* [ ] [text](destination)\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert := assert.New(t)
-			PrintMemoArchiveNodeHeadingStyle(tt.args.b, tt.args.tns)
+			gmw := tt.args.gmw
+			if gmw == nil {
+				gmw = markdown.NewGoldmarkWrapper()
+			}
+			PrintMemoArchiveNodeHeadingStyle(tt.args.b, tt.args.tns, gmw)
 			assert.Equal(tt.want, tt.args.b.String())
 		})
 	}
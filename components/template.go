@@ -8,26 +8,28 @@ import (
 )
 
 var (
-	// daily memo
-	HEADING_NAME_TITLE             = markdown.NewHeading(1, "daily memo")
-	HEADING_NAME_TODAYSMEMOARCHIVE = markdown.NewHeading(2, "today's memo archive")
-	HEADING_NAME_TODOS             = markdown.NewHeading(2, "todos")
-	HEADING_NAME_WANTTODOS         = markdown.NewHeading(2, "wanttodos")
-	HEADING_NAME_MEMOS             = markdown.NewHeading(2, "memos")
 	// weekly report
 	HEADING_NAME_WEEKLYREPORT = markdown.NewHeading(1, "Weekly Report")
 	// memo archives index
 	HEADING_NAME_MEMOARCHIVES_INDEX = markdown.NewHeading(1, "Memo Archives Index")
 )
 
+// markers of the region of memo archives index to regenerate, which takes precedence over the section of the heading
+const (
+	MARKER_MEMOARCHIVES_INDEX_BEGIN = "<!-- memo:archives:begin -->"
+	MARKER_MEMOARCHIVES_INDEX_END   = "<!-- memo:archives:end -->"
+)
+
+// DefaultDailymemoSections is the structure of daily memos unless sections are declared in config
+var DefaultDailymemoSections = []models.Section{
+	{Heading: "daily memo", Level: 1, Behavior: models.SECTIONBEHAVIOR_GENERATED},
+	{Heading: "today's memo archive", Level: 2, Behavior: models.SECTIONBEHAVIOR_ARCHIVE},
+	{Heading: "todos", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT},
+	{Heading: "wanttodos", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT},
+	{Heading: "memos", Level: 2, Behavior: models.SECTIONBEHAVIOR_STATIC, Memos: true},
+}
+
 var (
-	dailymemoHeadings = []markdown.Heading{
-		HEADING_NAME_TITLE,
-		HEADING_NAME_TODAYSMEMOARCHIVE,
-		HEADING_NAME_TODOS,
-		HEADING_NAME_WANTTODOS,
-		HEADING_NAME_MEMOS,
-	}
 	weeklyReportHeadings = []markdown.Heading{
 		HEADING_NAME_WEEKLYREPORT,
 	}
@@ -42,12 +44,23 @@ var (
 )
 
 var (
-	TemplateDailymemo         = models.NewTemplate(dailymemoHeadings)
 	TemplateWeeklyReport      = models.NewTemplate(weeklyReportHeadings)
 	TemplateMemoArchives      = models.NewTemplate(memoArchivesHeadings)
-	TemplateMemoArchivesIndex = models.NewTemplate(memoArchivesIndexHeadings)
+	TemplateMemoArchivesIndex = models.Template{
+		Headings: memoArchivesIndexHeadings,
+		Body:     MARKER_MEMOARCHIVES_INDEX_BEGIN + "\n" + MARKER_MEMOARCHIVES_INDEX_END + "\n",
+	}
 )
 
+// NewTemplateDailymemo returns the template of daily memos with the sections
+func NewTemplateDailymemo(sections []models.Section) models.Template {
+	headings := make([]markdown.Heading, 0, len(sections))
+	for _, s := range sections {
+		headings = append(headings, s.MarkdownHeading())
+	}
+	return models.NewTemplate(headings)
+}
+
 func GenerateTemplateString(t models.Template) string {
 	sb := strings.Builder{}
 	for i, h := range t.Headings {
@@ -56,5 +69,8 @@ func GenerateTemplateString(t models.Template) string {
 			sb.WriteString("\n")
 		}
 	}
+	if t.Body != "" {
+		sb.WriteString("\n" + t.Body)
+	}
 	return sb.String()
 }
@@ -40,7 +40,7 @@ func TestGenerateTemplateString(t *testing.T) {
 	}{
 		{
 			name: "templatedailymemo",
-			args: args{t: TemplateDailymemo},
+			args: args{t: NewTemplateDailymemo(DefaultDailymemoSections)},
 			want: string(dailyMemoGolden),
 		},
 		{
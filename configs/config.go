@@ -1,31 +1,62 @@
 package configs
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/hirotoni/memo/components"
 	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
 )
 
 const (
 	FOLDER_NAME_CONFIG       = ".config/memoapp/"
 	FOLDER_NAME_DAILYMEMO    = "dailymemo/"
 	FOLDER_NAME_MEMOARCHIVES = "memoarchives/"
+	FOLDER_NAME_STATE        = ".memo/"
 
 	FILE_NAME_CONFIG                = "config.toml"
 	FILE_NAME_DAILYMEMO_TEMPLATE    = "template.md"
 	FILE_NAME_MEMOARCHIVES_TEMPLATE = "template.md"
 	FILE_NAME_MEMOARCHIVES_INDEX    = "index.md"
 	FILE_NAME_WEEKLY_REPORT         = "weekly_report.md"
+	FILE_NAME_INDEX                 = "index.gob"
+	FILE_NAME_REVIEW                = "review.json"
+	FILE_NAME_HISTORY               = "history.jsonl"
+
+	DEFAULT_TIMEZONE = "Asia/Tokyo"
 )
 
 type TomlConfig struct {
-	BaseDir    string `toml:"basedir"`    // memoapp base directory
-	DaysToSeek int    `toml:"daystoseek"` // days to seek back
-	Gmw        *markdown.GoldmarkWrapper
+	BaseDir        string                    `toml:"basedir"`                  // memoapp base directory
+	DaysToSeek     int                       `toml:"daystoseek"`               // days to seek back
+	Editor         string                    `toml:"editor,omitempty"`         // editor command with placeholders {file}, {line} and {basedir}. defaults to $VISUAL or $EDITOR
+	Timezone       string                    `toml:"timezone,omitempty"`       // IANA time zone such as Europe/London, or Local for the system time zone. defaults to Asia/Tokyo
+	FilenameLayout string                    `toml:"filenamelayout,omitempty"` // layout of daily memo filenames in the format of the time package. defaults to 2006-01-02-Mon
+	WeekdayLocale  string                    `toml:"weekdaylocale,omitempty"`  // en or ja. the language of weekdays in filenames. defaults to en
+	EditMode       markdown.EditMode         `toml:"editmode,omitempty"`       // source or render. how sections are copied between memos. defaults to source, which keeps them as written
+	SlugStyle      markdown.SlugStyle        `toml:"slugstyle,omitempty"`      // github, gitlab or vscode. the viewer whose heading anchors links point at. defaults to github
+	Renderer       RendererConfig            `toml:"renderer,omitempty"`       // how sections are written in render mode
+	MemoArchives   MemoArchivesConfig        `toml:"memoarchives,omitempty"`   // how memo archives are picked for daily memos
+	Sections       []models.Section          `toml:"sections,omitempty"`       // sections of daily memos in order. defaults to components.DefaultDailymemoSections
+	Inherit        map[string]InheritConfig  `toml:"inherit,omitempty"`        // deprecated: use mode and keepchecked of sections. how to inherit each heading, keyed by heading text
+	Gmw            *markdown.GoldmarkWrapper `toml:"-"`
+}
+
+type InheritConfig struct {
+	Mode        models.InheritMode `toml:"mode"`        // copy, move or link. defaults to copy
+	KeepChecked bool               `toml:"keepchecked"` // inherit checked todos as well
 }
 
 func NewTomlConfig(baseDir string, daystoseek int, gmw *markdown.GoldmarkWrapper) *TomlConfig {
@@ -80,11 +111,99 @@ func LoadTomlConfig() *TomlConfig {
 		return nil
 	}
 
-	tomlConfig.Gmw = markdown.NewGoldmarkWrapper()
+	if err := tomlConfig.MemoArchives.Validate(); err != nil {
+		log.Fatal(err)
+		return nil
+	}
+	if err := tomlConfig.ValidateSections(); err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	rendererOptions, err := tomlConfig.Renderer.Options()
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+	tomlConfig.Gmw = markdown.NewGoldmarkWrapper(
+		markdown.WithEditMode(tomlConfig.EditMode),
+		markdown.WithRendererOptions(rendererOptions...),
+	)
 
 	return tomlConfig
 }
 
+// UpdateTomlConfig sets the top level keys of the config file to the values, leaving the rest of the file as written
+func UpdateTomlConfig(values map[string]string) error {
+	configFilePath, err := ConfigFilePath()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	updated, err := updateTomlKeys(b, values)
+	if err != nil {
+		return err
+	}
+	tmp := configFilePath + ".tmp"
+	if err := os.WriteFile(tmp, updated, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, configFilePath)
+}
+
+// updateTomlKeys replaces the lines of the top level keys with the values, or adds them before the first table if missing.
+// It returns an error if the result does not decode to the values.
+func updateTomlKeys(source []byte, values map[string]string) ([]byte, error) {
+	lines := strings.SplitAfter(string(source), "\n")
+	firstTable := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			firstTable = i
+			break
+		}
+	}
+
+	var missing []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(map[string]string{key: values[key]}); err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(lines[:firstTable], func(line string) bool {
+			k, _, found := strings.Cut(line, "=")
+			return found && strings.Trim(strings.TrimSpace(k), `"'`) == key
+		})
+		if i < 0 {
+			missing = append(missing, buf.String())
+			continue
+		}
+		lines[i] = buf.String()
+	}
+	at := firstTable
+	for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+		at-- // keep the blank lines before the table
+	}
+	if len(missing) > 0 && at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += "\n"
+	}
+	lines = slices.Insert(lines, at, missing...)
+	updated := []byte(strings.Join(lines, ""))
+
+	var decoded map[string]any
+	if _, err := toml.Decode(string(updated), &decoded); err != nil {
+		return nil, fmt.Errorf("config would be broken: %w", err)
+	}
+	for key, value := range values {
+		if decoded[key] != value {
+			return nil, fmt.Errorf("config would not be updated: %s", key)
+		}
+	}
+	return updated, nil
+}
+
 func ConfigDirPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -107,11 +226,35 @@ func (tc *TomlConfig) DailymemoDir() string {
 func (tc *TomlConfig) DailymemoTemplateFile() string {
 	return filepath.Join(tc.BaseDir, FOLDER_NAME_DAILYMEMO, FILE_NAME_DAILYMEMO_TEMPLATE) // {basedir}/dailymemo/template.md
 }
+
+// DailymemoTemplateFiles returns template files for the date in order of precedence: the day of month, the weekday, and the default
+func (tc *TomlConfig) DailymemoTemplateFiles(date time.Time) []string {
+	name := strings.TrimSuffix(FILE_NAME_DAILYMEMO_TEMPLATE, ".md")
+	return []string{
+		filepath.Join(tc.BaseDir, FOLDER_NAME_DAILYMEMO, fmt.Sprintf("%s.day%02d.md", name, date.Day())),                     // {basedir}/dailymemo/template.day01.md
+		filepath.Join(tc.BaseDir, FOLDER_NAME_DAILYMEMO, fmt.Sprintf("%s.%s.md", name, strings.ToLower(date.Format("Mon")))), // {basedir}/dailymemo/template.mon.md
+		tc.DailymemoTemplateFile(),
+	}
+}
 func (tc *TomlConfig) WeeklyReportFile() string {
 	return filepath.Join(tc.BaseDir, FOLDER_NAME_DAILYMEMO, FILE_NAME_WEEKLY_REPORT) // {basedir}/dailymemo/weekly_report.md
 }
+
+// MemoArchivesDir returns the root of memo archives, which is {basedir}/memoarchives unless configured
 func (tc *TomlConfig) MemoArchivesDir() string {
-	return filepath.Join(tc.BaseDir, FOLDER_NAME_MEMOARCHIVES) // {basedir}/memoarchives
+	root := tc.MemoArchives.Root
+	if root == "" {
+		return filepath.Join(tc.BaseDir, FOLDER_NAME_MEMOARCHIVES) // {basedir}/memoarchives
+	}
+	if rest, ok := strings.CutPrefix(root, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			root = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(tc.BaseDir, root)
+	}
+	return filepath.Clean(root)
 }
 func (tc *TomlConfig) MemoArchivesTemplateFile() string {
 	return filepath.Join(tc.BaseDir, FOLDER_NAME_MEMOARCHIVES, FILE_NAME_MEMOARCHIVES_TEMPLATE) // {basedir}/memoarchives/template.md
@@ -119,3 +262,119 @@ func (tc *TomlConfig) MemoArchivesTemplateFile() string {
 func (tc *TomlConfig) MemoArchivesIndexFile() string {
 	return filepath.Join(tc.BaseDir, FOLDER_NAME_MEMOARCHIVES, FILE_NAME_MEMOARCHIVES_INDEX) // {basedir}/memoarchives/index.md
 }
+
+func (tc *TomlConfig) StateDir() string {
+	return filepath.Join(tc.BaseDir, FOLDER_NAME_STATE) // {basedir}/.memo
+}
+
+func (tc *TomlConfig) IndexFile() string {
+	return filepath.Join(tc.BaseDir, FOLDER_NAME_STATE, FILE_NAME_INDEX) // {basedir}/.memo/index.gob
+}
+
+// IndexFingerprint returns a hash of the config that memos in the index are found under: the sections and the slug style
+func (tc *TomlConfig) IndexFingerprint() string {
+	b, err := json.Marshal(struct {
+		Sections  []models.Section
+		SlugStyle markdown.SlugStyle
+	}{tc.DailymemoSections(), tc.SlugStyle})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+func (tc *TomlConfig) ReviewFile() string {
+	return filepath.Join(tc.BaseDir, FOLDER_NAME_STATE, FILE_NAME_REVIEW) // {basedir}/.memo/review.json
+}
+
+func (tc *TomlConfig) HistoryFile() string {
+	return filepath.Join(tc.BaseDir, FOLDER_NAME_STATE, FILE_NAME_HISTORY) // {basedir}/.memo/history.jsonl
+}
+
+// DailymemoSections returns the sections of daily memos with defaults filled in
+func (tc *TomlConfig) DailymemoSections() []models.Section {
+	sections := slices.Clone(tc.Sections)
+	if len(sections) == 0 {
+		sections = slices.Clone(components.DefaultDailymemoSections)
+	}
+	for i, s := range sections {
+		if s.Level == 0 {
+			s.Level = 2
+		}
+		if s.Behavior == "" {
+			s.Behavior = models.SECTIONBEHAVIOR_STATIC
+		}
+		if s.Behavior == models.SECTIONBEHAVIOR_INHERIT {
+			if ic, ok := tc.Inherit[s.Heading]; ok {
+				if s.Mode == "" {
+					s.Mode = ic.Mode
+				}
+				s.KeepChecked = s.KeepChecked || ic.KeepChecked
+			}
+			if s.Mode == "" {
+				s.Mode = models.INHERITMODE_COPY
+			}
+		}
+		sections[i] = s
+	}
+	return sections
+}
+
+// ValidateSections returns an error if a section of daily memos, or the deprecated inherit table, has a value out of the choices
+func (tc *TomlConfig) ValidateSections() error {
+	behaviors := []models.SectionBehavior{models.SECTIONBEHAVIOR_STATIC, models.SECTIONBEHAVIOR_INHERIT, models.SECTIONBEHAVIOR_ARCHIVE, models.SECTIONBEHAVIOR_GENERATED}
+	modes := []models.InheritMode{models.INHERITMODE_COPY, models.INHERITMODE_MOVE, models.INHERITMODE_LINK}
+	for heading, ic := range tc.Inherit {
+		if ic.Mode != "" && !slices.Contains(modes, ic.Mode) {
+			return fmt.Errorf("unsupported inherit mode of %s: %s", heading, ic.Mode)
+		}
+	}
+	for _, s := range tc.DailymemoSections() {
+		if s.Level < 1 || s.Level > 6 {
+			return fmt.Errorf("level of section %s must be between 1 and 6: %d", s.Heading, s.Level)
+		}
+		if !slices.Contains(behaviors, s.Behavior) {
+			return fmt.Errorf("unsupported behavior of section %s: %s", s.Heading, s.Behavior)
+		}
+		if s.Mode != "" && !slices.Contains(modes, s.Mode) {
+			return fmt.Errorf("unsupported inherit mode of section %s: %s", s.Heading, s.Mode)
+		}
+	}
+	return nil
+}
+
+// MemosSection returns the section whose subsections are memos. ok is false if no section is for memos.
+func (tc *TomlConfig) MemosSection() (section models.Section, ok bool) {
+	for _, s := range tc.DailymemoSections() {
+		if s.Memos {
+			return s, true
+		}
+	}
+	return models.Section{}, false
+}
+
+// Location returns the time zone in which memo dates are decided
+func (tc *TomlConfig) Location() (*time.Location, error) {
+	if tc.Timezone == "" {
+		return time.LoadLocation(DEFAULT_TIMEZONE)
+	}
+	return time.LoadLocation(tc.Timezone)
+}
+
+// Slugger returns a new slugger for a document in the slug style
+func (tc *TomlConfig) Slugger() *markdown.Slugger {
+	s, err := markdown.NewSlugger(tc.SlugStyle)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+// DateLayout returns the layout of daily memo filenames
+func (tc *TomlConfig) DateLayout() DateLayout {
+	dl, err := NewDateLayout(tc.FilenameLayout, tc.WeekdayLocale)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return dl
+}
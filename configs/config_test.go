@@ -0,0 +1,245 @@
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memo/components"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTomlConfig_DailymemoSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   TomlConfig
+		expected []models.Section
+	}{
+		{
+			name:   "defaults",
+			config: TomlConfig{Inherit: map[string]InheritConfig{"wanttodos": {Mode: models.INHERITMODE_LINK}}},
+			expected: []models.Section{
+				components.DefaultDailymemoSections[0],
+				components.DefaultDailymemoSections[1],
+				{Heading: "todos", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_COPY},
+				{Heading: "wanttodos", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_LINK},
+				components.DefaultDailymemoSections[4],
+			},
+		},
+		{
+			name: "declared sections",
+			config: TomlConfig{Sections: []models.Section{
+				{Heading: "standup", Level: 1, Behavior: models.SECTIONBEHAVIOR_GENERATED},
+				{Heading: "blockers", Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_MOVE},
+				{Heading: "log", Memos: true},
+			}},
+			expected: []models.Section{
+				{Heading: "standup", Level: 1, Behavior: models.SECTIONBEHAVIOR_GENERATED},
+				{Heading: "blockers", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_MOVE},
+				{Heading: "log", Level: 2, Behavior: models.SECTIONBEHAVIOR_STATIC, Memos: true},
+			},
+		},
+		{
+			name: "deprecated inherit table",
+			config: TomlConfig{
+				Sections: []models.Section{{Heading: "todos", Behavior: models.SECTIONBEHAVIOR_INHERIT}},
+				Inherit:  map[string]InheritConfig{"todos": {Mode: models.INHERITMODE_LINK, KeepChecked: true}},
+			},
+			expected: []models.Section{
+				{Heading: "todos", Level: 2, Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_LINK, KeepChecked: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.DailymemoSections())
+		})
+	}
+}
+
+func TestTomlConfig_ValidateSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TomlConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: TomlConfig{}},
+		{name: "declared sections", config: TomlConfig{Sections: []models.Section{
+			{Heading: "standup", Level: 1, Behavior: models.SECTIONBEHAVIOR_GENERATED},
+			{Heading: "blockers", Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: models.INHERITMODE_MOVE},
+			{Heading: "log", Memos: true},
+		}}},
+		{name: "behavior", config: TomlConfig{Sections: []models.Section{{Heading: "todos", Behavior: "inhert"}}}, wantErr: true},
+		{name: "mode", config: TomlConfig{Sections: []models.Section{{Heading: "todos", Behavior: models.SECTIONBEHAVIOR_INHERIT, Mode: "mvoe"}}}, wantErr: true},
+		{name: "level", config: TomlConfig{Sections: []models.Section{{Heading: "todos", Level: 7}}}, wantErr: true},
+		{name: "negative level", config: TomlConfig{Sections: []models.Section{{Heading: "todos", Level: -1}}}, wantErr: true},
+		{name: "deprecated inherit table", config: TomlConfig{Inherit: map[string]InheritConfig{"todos": {Mode: "lnk"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidateSections()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRendererConfig_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RendererConfig
+		want    int
+		wantErr bool
+	}{
+		{name: "defaults", config: RendererConfig{}, want: 0},
+		{name: "all", config: RendererConfig{BulletMarker: "-", OrderedList: "one", Emphasis: "_", ListIndent: 4, BlankLines: true, LineEnding: "crlf"}, want: 6},
+		{name: "bullet marker", config: RendererConfig{BulletMarker: "x"}, wantErr: true},
+		{name: "ordered list", config: RendererConfig{OrderedList: "zero"}, wantErr: true},
+		{name: "emphasis", config: RendererConfig{Emphasis: "**"}, wantErr: true},
+		{name: "list indent", config: RendererConfig{ListIndent: -1}, wantErr: true},
+		{name: "line ending", config: RendererConfig{LineEnding: "cr"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.config.Options()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, opts, tt.want)
+		})
+	}
+}
+
+func TestMemoArchivesConfig_Weight(t *testing.T) {
+	mc := MemoArchivesConfig{Weights: map[string]float64{
+		"go":              2,
+		"go/concurrency/": 0.5,
+		"sql/SQL notes":   3,
+	}}
+	tests := []struct {
+		category string
+		want     float64
+	}{
+		{category: "go/Go notes", want: 2},
+		{category: "go/concurrency/Channels", want: 0.5},
+		{category: "golang/Go notes", want: 1},
+		{category: "sql/SQL notes", want: 3},
+		{category: "sql/SQL notes 2", want: 1},
+		{category: "Misc", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			assert.Equal(t, tt.want, mc.Weight(tt.category))
+		})
+	}
+}
+
+func TestMemoArchivesConfig_Skips(t *testing.T) {
+	mc := MemoArchivesConfig{
+		Include: []string{"*.md"},
+		Exclude: []string{"drafts", "go/**/old-*.md", "/assets"},
+	}
+	tests := []struct {
+		relpath string
+		isDir   bool
+		want    bool
+	}{
+		{relpath: "go/notes.md", want: false},
+		{relpath: "go/image.png", want: true},
+		{relpath: "drafts", isDir: true, want: true},
+		{relpath: "go/drafts", isDir: true, want: true},
+		{relpath: "go/concurrency/old-channels.md", want: true},
+		{relpath: "go/old-notes.md", want: true},
+		{relpath: "old-notes.md", want: false},
+		{relpath: "assets", isDir: true, want: true},
+		{relpath: "go/assets", isDir: true, want: false},
+		{relpath: ".obsidian", isDir: true, want: true},
+		{relpath: "go", isDir: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.relpath, func(t *testing.T) {
+			assert.Equal(t, tt.want, mc.Skips(tt.relpath, tt.isDir))
+		})
+	}
+}
+
+func TestTomlConfig_IndexFingerprint(t *testing.T) {
+	assert := assert.New(t)
+	tc := &TomlConfig{}
+	fingerprint := tc.IndexFingerprint()
+	assert.Equal(fingerprint, (&TomlConfig{DaysToSeek: 3}).IndexFingerprint())
+
+	tc.SlugStyle = markdown.SLUGSTYLE_GITLAB
+	assert.NotEqual(fingerprint, tc.IndexFingerprint())
+
+	tc = &TomlConfig{Sections: []models.Section{{Heading: "notes", Level: 2, Memos: true}}}
+	assert.NotEqual(fingerprint, tc.IndexFingerprint())
+}
+
+func TestTomlConfig_Location(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		want     string
+	}{
+		{name: "default", timezone: "", want: "Asia/Tokyo"},
+		{name: "configured", timezone: "Europe/London", want: "Europe/London"},
+		{name: "system", timezone: "Local", want: time.Local.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			tc := TomlConfig{Timezone: tt.timezone}
+			got, err := tc.Location()
+			assert.NoError(err)
+			assert.Equal(tt.want, got.String())
+		})
+	}
+}
+
+func TestUpdateTomlKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		values  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "replaced in place",
+			source: "# my memos\nbasedir = \"/memo\"\nfilenamelayout = \"2006-01-02-Mon\"\ndaystoseek = 10\n\n[renderer]\nbulletmarker = \"-\"\n",
+			values: map[string]string{"filenamelayout": "2006年01月02日(Mon)"},
+			want:   "# my memos\nbasedir = \"/memo\"\nfilenamelayout = \"2006年01月02日(Mon)\"\ndaystoseek = 10\n\n[renderer]\nbulletmarker = \"-\"\n",
+		},
+		{
+			name:   "added before the first table",
+			source: "basedir = \"/memo\"\n\n[renderer]\nfilenamelayout = \"not top level\"\n",
+			values: map[string]string{"filenamelayout": "2006-01-02", "weekdaylocale": "ja"},
+			want:   "basedir = \"/memo\"\nfilenamelayout = \"2006-01-02\"\nweekdaylocale = \"ja\"\n\n[renderer]\nfilenamelayout = \"not top level\"\n",
+		},
+		{
+			name:    "broken",
+			source:  "basedir = \n",
+			values:  map[string]string{"filenamelayout": "2006-01-02"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := updateTomlKeys([]byte(tt.source), tt.values)
+			if tt.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package configs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_FILENAME_LAYOUT = "2006-01-02-Mon"
+
+	WEEKDAYLOCALE_EN = "en"
+	WEEKDAYLOCALE_JA = "ja"
+)
+
+// weekday names indexed by time.Weekday
+var weekdayNames = map[string]struct{ short, long [7]string }{
+	WEEKDAYLOCALE_EN: {
+		short: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		long:  [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	},
+	WEEKDAYLOCALE_JA: {
+		short: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		long:  [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+	},
+}
+
+// layout elements of the time package, longest first so that e.g. "January" wins over "Jan"
+var layoutElements = []struct {
+	element string
+	pattern string
+}{
+	{"January", `[A-Za-z]+`},
+	{"Monday", ``}, // weekday, pattern depends on the locale
+	{"2006", `\d{4}`},
+	{"002", `\d{3}`},
+	{"Jan", `[A-Za-z]{3}`},
+	{"Mon", ``}, // weekday, pattern depends on the locale
+	{"MST", `[A-Z]{3,4}`},
+	{"01", `\d{2}`},
+	{"02", `\d{2}`},
+	{"06", `\d{2}`},
+	{"15", `\d{2}`},
+	{"03", `\d{2}`},
+	{"04", `\d{2}`},
+	{"05", `\d{2}`},
+	{"_2", `[ \d]\d`},
+	{"PM", `[AP]M`},
+	{"pm", `[ap]m`},
+	{"1", `\d{1,2}`},
+	{"2", `\d{1,2}`},
+	{"3", `\d{1,2}`},
+	{"4", `\d{1,2}`},
+	{"5", `\d{1,2}`},
+}
+
+// DateLayout formats and parses dates in daily memo filenames.
+// It works like the layout of the time package, except that weekdays are written in the locale.
+type DateLayout struct {
+	Layout string
+	Locale string
+	chunks []layoutChunk
+}
+
+type layoutChunk struct {
+	text    string // literal text or layout element
+	element bool
+	pattern string // regular expression of the element
+}
+
+func NewDateLayout(layout, locale string) (DateLayout, error) {
+	if layout == "" {
+		layout = DEFAULT_FILENAME_LAYOUT
+	}
+	if locale == "" {
+		locale = WEEKDAYLOCALE_EN
+	}
+	names, ok := weekdayNames[locale]
+	if !ok {
+		return DateLayout{}, fmt.Errorf("unsupported weekday locale: %s", locale)
+	}
+
+	dl := DateLayout{Layout: layout, Locale: locale}
+	var literal strings.Builder
+	var hasYear, hasMonth, hasDay bool
+	for rest := layout; rest != ""; {
+		matched := false
+		for _, e := range layoutElements {
+			if !strings.HasPrefix(rest, e.element) {
+				continue
+			}
+			pattern := e.pattern
+			switch e.element {
+			case "Monday":
+				pattern = alternation(names.long[:])
+			case "Mon":
+				pattern = alternation(names.short[:])
+			case "2006", "06":
+				hasYear = true
+			case "January", "Jan", "01", "1":
+				hasMonth = true
+			case "02", "_2", "2", "002":
+				hasDay = true
+			}
+			if literal.Len() > 0 {
+				dl.chunks = append(dl.chunks, layoutChunk{text: literal.String()})
+				literal.Reset()
+			}
+			dl.chunks = append(dl.chunks, layoutChunk{text: e.element, element: true, pattern: pattern})
+			rest = rest[len(e.element):]
+			matched = true
+			break
+		}
+		if !matched {
+			literal.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+	if literal.Len() > 0 {
+		dl.chunks = append(dl.chunks, layoutChunk{text: literal.String()})
+	}
+
+	if !hasYear || !hasMonth || !hasDay {
+		return DateLayout{}, fmt.Errorf("layout must contain year, month and day: %s", layout)
+	}
+	return dl, nil
+}
+
+func alternation(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = regexp.QuoteMeta(n)
+	}
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
+
+// Format returns the date formatted with the layout
+func (dl DateLayout) Format(date time.Time) string {
+	names := weekdayNames[dl.Locale]
+	var sb strings.Builder
+	for _, c := range dl.chunks {
+		switch {
+		case !c.element:
+			sb.WriteString(c.text)
+		case c.text == "Monday":
+			sb.WriteString(names.long[date.Weekday()])
+		case c.text == "Mon":
+			sb.WriteString(names.short[date.Weekday()])
+		default:
+			sb.WriteString(date.Format(c.text))
+		}
+	}
+	return sb.String()
+}
+
+// Weekday returns the short name of the weekday of the date in the locale
+func (dl DateLayout) Weekday(date time.Time) string {
+	return weekdayNames[dl.Locale].short[date.Weekday()]
+}
+
+// Parse parses the string formatted with the layout as a date in the location
+func (dl DateLayout) Parse(s string, loc *time.Location) (time.Time, error) {
+	m := dl.compile().FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%q does not match layout %q", s, dl.Layout)
+	}
+
+	// weekdays are redundant, so parse the rest with the time package
+	var layout, value strings.Builder
+	for i, c := range dl.chunks {
+		if c.element && (c.text == "Monday" || c.text == "Mon") {
+			continue
+		}
+		layout.WriteString(c.text)
+		value.WriteString(m[i+1])
+	}
+	return time.ParseInLocation(layout.String(), value.String(), loc)
+}
+
+// Pattern returns the regular expression, not anchored, that matches strings formatted with the layout
+func (dl DateLayout) Pattern() string {
+	var sb strings.Builder
+	for _, c := range dl.chunks {
+		if c.element {
+			sb.WriteString("(" + c.pattern + ")")
+		} else {
+			sb.WriteString("(" + regexp.QuoteMeta(c.text) + ")")
+		}
+	}
+	return sb.String()
+}
+
+// FilenameRegexp returns the regular expression that matches daily memo filenames
+func (dl DateLayout) FilenameRegexp() *regexp.Regexp {
+	return regexp.MustCompile(`^` + dl.Pattern() + `\.md$`)
+}
+
+func (dl DateLayout) compile() *regexp.Regexp {
+	return regexp.MustCompile(`^` + dl.Pattern() + `$`)
+}
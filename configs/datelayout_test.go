@@ -0,0 +1,75 @@
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateLayout(t *testing.T) {
+	date := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		layout    string
+		locale    string
+		formatted string
+		matches   []string
+		unmatches []string
+	}{
+		{
+			name:      "default",
+			formatted: "2025-01-06-Mon",
+			matches:   []string{"2025-01-06-Mon.md", "2024-12-31-Tue.md"},
+			unmatches: []string{"2025-01-06-Mon.md.bak", "2025-01-06-Foo.md", "template.md", "2025-01-06.md"},
+		},
+		{
+			name:      "japanese weekdays",
+			locale:    WEEKDAYLOCALE_JA,
+			formatted: "2025-01-06-月",
+			matches:   []string{"2025-01-06-月.md"},
+			unmatches: []string{"2025-01-06-Mon.md"},
+		},
+		{
+			name:      "long weekday and no separators",
+			layout:    "20060102_Monday",
+			formatted: "20250106_Monday",
+			matches:   []string{"20250106_Monday.md"},
+			unmatches: []string{"20250106_Mon.md"},
+		},
+		{
+			name:      "japanese long weekdays with literals",
+			layout:    "2006年01月02日(Monday)",
+			locale:    WEEKDAYLOCALE_JA,
+			formatted: "2025年01月06日(月曜日)",
+			matches:   []string{"2025年01月06日(月曜日).md"},
+			unmatches: []string{"2025年01月06日月曜日.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			dl, err := NewDateLayout(tt.layout, tt.locale)
+			assert.NoError(err)
+
+			assert.Equal(tt.formatted, dl.Format(date))
+			parsed, err := dl.Parse(tt.formatted, time.UTC)
+			assert.NoError(err)
+			assert.Equal(date, parsed)
+
+			for _, m := range tt.matches {
+				assert.True(dl.FilenameRegexp().MatchString(m), m)
+			}
+			for _, m := range tt.unmatches {
+				assert.False(dl.FilenameRegexp().MatchString(m), m)
+			}
+		})
+	}
+}
+
+func TestNewDateLayout_Invalid(t *testing.T) {
+	_, err := NewDateLayout("2006-01", "")
+	assert.Error(t, err)
+	_, err = NewDateLayout("", "fr")
+	assert.Error(t, err)
+}
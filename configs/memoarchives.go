@@ -0,0 +1,101 @@
+package configs
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MemoArchivesConfig is where memo archives are and how they are picked for daily memos.
+// A category is the directory of a memo archive and its level 1 heading, such as "go/concurrency/Go notes".
+// Glob patterns are matched against paths relative to the root with slashes, where ** matches any number of directories,
+// and a pattern without a slash matches the name at any depth, while one starting with a slash only matches at the root.
+type MemoArchivesConfig struct {
+	Root    string             `toml:"root,omitempty"`    // directory of memo archives, absolute, starting with ~/ or relative to the base dir. defaults to {basedir}/memoarchives. the index stays in {basedir}/memoarchives
+	Include []string           `toml:"include,omitempty"` // glob patterns of memo archive files. defaults to every markdown file
+	Exclude []string           `toml:"exclude,omitempty"` // glob patterns of files and directories to skip, such as drafts or assets. hidden directories are always skipped
+	Picks   int                `toml:"picks,omitempty"`   // memo archives to pick each day, no two from the same category. defaults to 1
+	Weights map[string]float64 `toml:"weights,omitempty"` // how often a category is picked relative to others, keyed by the category or a directory above it. defaults to 1, and 0 never picks it
+}
+
+// Validate returns an error if the config has a value out of range
+func (mc MemoArchivesConfig) Validate() error {
+	if mc.Picks < 0 {
+		return fmt.Errorf("picks of memo archives must not be negative: %d", mc.Picks)
+	}
+	for _, p := range append(slices.Clone(mc.Include), mc.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern of memo archives: %s", p)
+		}
+	}
+	for k, w := range mc.Weights {
+		if w < 0 {
+			return fmt.Errorf("weight of memo archives must not be negative: %s = %v", k, w)
+		}
+	}
+	return nil
+}
+
+// PicksPerDay returns how many memo archives to pick each day
+func (mc MemoArchivesConfig) PicksPerDay() int {
+	if mc.Picks == 0 {
+		return 1
+	}
+	return mc.Picks
+}
+
+// Weight returns the weight of the category, which is of the longest key the category is in
+func (mc MemoArchivesConfig) Weight(category string) float64 {
+	weight, longest := 1.0, -1
+	for k, w := range mc.Weights {
+		k = strings.Trim(k, "/")
+		if (category == k || strings.HasPrefix(category, k+"/")) && len(k) > longest {
+			weight, longest = w, len(k)
+		}
+	}
+	return weight
+}
+
+// Skips reports whether the file or directory at the path relative to the root is not of memo archives
+func (mc MemoArchivesConfig) Skips(relpath string, isDir bool) bool {
+	relpath = path.Clean(relpath)
+	if isDir && relpath != "." && strings.HasPrefix(path.Base(relpath), ".") {
+		return true
+	}
+	if slices.ContainsFunc(mc.Exclude, func(p string) bool { return matchGlob(p, relpath) }) {
+		return true
+	}
+	if isDir || len(mc.Include) == 0 {
+		return false
+	}
+	return !slices.ContainsFunc(mc.Include, func(p string) bool { return matchGlob(p, relpath) })
+}
+
+// matchGlob reports whether the slash separated path matches the pattern, where ** matches zero or more directories
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(strings.TrimRight(pattern, "/"), "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.Trim(pattern, "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	return err == nil && ok && matchSegments(pattern[1:], name[1:])
+}
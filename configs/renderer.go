@@ -0,0 +1,61 @@
+package configs
+
+import (
+	"fmt"
+
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
+	"github.com/yuin/goldmark/renderer"
+)
+
+// RendererConfig is how markdown is written when sections are rendered, e.g. to follow markdownlint rules
+type RendererConfig struct {
+	BulletMarker string                      `toml:"bulletmarker,omitempty"` // -, * or +. defaults to the marker as written
+	OrderedList  myrenderer.OrderedListStyle `toml:"orderedlist,omitempty"`  // sequential or one. defaults to sequential
+	Emphasis     string                      `toml:"emphasis,omitempty"`     // * or _. defaults to *
+	ListIndent   int                         `toml:"listindent,omitempty"`   // columns from a list marker to the content. defaults to the indent as written
+	BlankLines   bool                        `toml:"blanklines,omitempty"`   // separate every block by a blank line. defaults to blank lines as written
+	LineEnding   myrenderer.LineEnding       `toml:"lineending,omitempty"`   // lf or crlf. defaults to lf
+}
+
+// Options returns the renderer options for the config
+func (rc RendererConfig) Options() ([]renderer.Option, error) {
+	var opts []renderer.Option
+	switch rc.BulletMarker {
+	case "":
+	case "-", "*", "+":
+		opts = append(opts, myrenderer.WithBulletMarker(rc.BulletMarker[0]))
+	default:
+		return nil, fmt.Errorf("unsupported bullet marker: %s", rc.BulletMarker)
+	}
+	switch rc.OrderedList {
+	case "":
+	case myrenderer.ORDEREDLISTSTYLE_SEQUENTIAL, myrenderer.ORDEREDLISTSTYLE_ONE:
+		opts = append(opts, myrenderer.WithOrderedListStyle(rc.OrderedList))
+	default:
+		return nil, fmt.Errorf("unsupported ordered list style: %s", rc.OrderedList)
+	}
+	switch rc.Emphasis {
+	case "":
+	case "*", "_":
+		opts = append(opts, myrenderer.WithEmphasisMarker(rc.Emphasis[0]))
+	default:
+		return nil, fmt.Errorf("unsupported emphasis marker: %s", rc.Emphasis)
+	}
+	if rc.ListIndent < 0 {
+		return nil, fmt.Errorf("invalid list indent: %d", rc.ListIndent)
+	}
+	if rc.ListIndent > 0 {
+		opts = append(opts, myrenderer.WithListIndent(rc.ListIndent))
+	}
+	if rc.BlankLines {
+		opts = append(opts, myrenderer.WithBlankLineBetweenBlocks(true))
+	}
+	switch rc.LineEnding {
+	case "":
+	case myrenderer.LINEENDING_LF, myrenderer.LINEENDING_CRLF:
+		opts = append(opts, myrenderer.WithLineEnding(rc.LineEnding))
+	default:
+		return nil, fmt.Errorf("unsupported line ending: %s", rc.LineEnding)
+	}
+	return opts, nil
+}
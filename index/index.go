@@ -0,0 +1,253 @@
+package index
+
+import (
+	"encoding/gob"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hirotoni/memo/models"
+)
+
+// VERSION is bumped whenever the on-disk format or the tokenizer changes, so that stale indexes are rebuilt
+const VERSION = 2
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+type Document struct {
+	Path    string // relative to the base dir
+	ModTime time.Time
+	Size    int64
+	Length  int            // number of terms
+	Memos   []*models.Memo // memos found in the document, used for link discovery
+}
+
+// Index is an inverted index of memo files persisted under the base dir
+type Index struct {
+	Version     int
+	Fingerprint string // of the config the memos were found under, so that changing the config rebuilds the index
+	Docs        map[string]*Document
+	Postings    map[string]map[string]int // term -> path -> term frequency
+}
+
+type Result struct {
+	Path  string
+	Score float64
+}
+
+func New(fingerprint string) *Index {
+	return &Index{
+		Version:     VERSION,
+		Fingerprint: fingerprint,
+		Docs:        map[string]*Document{},
+		Postings:    map[string]map[string]int{},
+	}
+}
+
+// Load loads the index from the file. An empty index is returned when the file does not exist, is outdated,
+// or was built under a config of another fingerprint.
+func Load(path string, fingerprint string) (*Index, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(fingerprint), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil || idx.Version != VERSION || idx.Fingerprint != fingerprint {
+		return New(fingerprint), nil
+	}
+	return idx, nil
+}
+
+// Save writes the index to the file
+func (idx *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(idx); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Stale reports whether the document at the path is missing or has changed since it was indexed
+func (idx *Index) Stale(path string, modTime time.Time, size int64) bool {
+	doc, ok := idx.Docs[path]
+	return !ok || !doc.ModTime.Equal(modTime) || doc.Size != size
+}
+
+// Put indexes the content as the document, replacing the previous one at the same path
+func (idx *Index) Put(doc *Document, content string) {
+	idx.Remove(doc.Path)
+
+	terms := Tokenize(content)
+	doc.Length = len(terms)
+	idx.Docs[doc.Path] = doc
+	for _, t := range terms {
+		if idx.Postings[t] == nil {
+			idx.Postings[t] = map[string]int{}
+		}
+		idx.Postings[t][doc.Path]++
+	}
+}
+
+// Remove removes the document at the path from the index
+func (idx *Index) Remove(path string) {
+	if _, ok := idx.Docs[path]; !ok {
+		return
+	}
+	delete(idx.Docs, path)
+	for t, ps := range idx.Postings {
+		delete(ps, path)
+		if len(ps) == 0 {
+			delete(idx.Postings, t)
+		}
+	}
+}
+
+// Paths returns paths of the indexed documents in lexical order
+func (idx *Index) Paths() []string {
+	paths := make([]string, 0, len(idx.Docs))
+	for p := range idx.Docs {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Memos returns memos of all the indexed documents ordered by path
+func (idx *Index) Memos() []*models.Memo {
+	var memos []*models.Memo
+	for _, p := range idx.Paths() {
+		memos = append(memos, idx.Docs[p].Memos...)
+	}
+	return memos
+}
+
+// Candidates returns paths of the documents that may contain the query as a substring, in lexical order.
+// Each term of the query must be a part of a term of the document, e.g. "deplo" of "deploy".
+func (idx *Index) Candidates(query string) []string {
+	expanded := idx.expand(query)
+	if expanded == nil {
+		return nil
+	}
+	var paths []string
+	for p := range idx.Docs {
+		if idx.containsAll(p, expanded) {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Search returns documents that may contain the query as a substring, ranked by BM25
+// with the frequency of a query term taken as the sum of the frequencies of the indexed terms it is a part of
+func (idx *Index) Search(query string) []Result {
+	expanded := idx.expand(query)
+	candidates := idx.Candidates(query)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var total int
+	for _, d := range idx.Docs {
+		total += d.Length
+	}
+	n := float64(len(idx.Docs))
+	avgdl := float64(total) / n
+
+	idfs := make([]float64, len(expanded))
+	for i, terms := range expanded {
+		docs := map[string]bool{}
+		for _, t := range terms {
+			for d := range idx.Postings[t] {
+				docs[d] = true
+			}
+		}
+		df := float64(len(docs))
+		idfs[i] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	results := make([]Result, 0, len(candidates))
+	for _, p := range candidates {
+		dl := float64(idx.Docs[p].Length)
+		var score float64
+		for i, terms := range expanded {
+			var tf float64
+			for _, t := range terms {
+				tf += float64(idx.Postings[t][p])
+			}
+			score += idfs[i] * tf * (k1 + 1) / (tf + k1*(1-b+b*dl/avgdl))
+		}
+		results = append(results, Result{Path: p, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// expand returns the indexed terms that each term of the query is a part of. It returns nil if the query has no terms.
+func (idx *Index) expand(query string) [][]string {
+	terms := uniq(Tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	expanded := make([][]string, len(terms))
+	for i, q := range terms {
+		for t := range idx.Postings {
+			if strings.Contains(t, q) {
+				expanded[i] = append(expanded[i], t)
+			}
+		}
+	}
+	return expanded
+}
+
+// containsAll reports whether the document contains one of the terms of each group
+func (idx *Index) containsAll(path string, groups [][]string) bool {
+	for _, terms := range groups {
+		if !slices.ContainsFunc(terms, func(t string) bool {
+			_, ok := idx.Postings[t][path]
+			return ok
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func uniq(terms []string) []string {
+	seen := map[string]bool{}
+	var ret []string
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
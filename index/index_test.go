@@ -0,0 +1,84 @@
+package index
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memo/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() *Index {
+	idx := New("")
+	idx.Put(&Document{Path: "a.md"}, "deploy notes: deploy the api, deploy again")
+	idx.Put(&Document{Path: "b.md"}, "deploy once. lots of other words about lunch and sushi")
+	idx.Put(&Document{Path: "c.md"}, "デプロイ手順書")
+	return idx
+}
+
+func TestIndex_Search(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "ranked by relevance", query: "deploy", want: []string{"a.md", "b.md"}},
+		{name: "all terms required", query: "deploy sushi", want: []string{"b.md"}},
+		{name: "japanese substring", query: "手順", want: []string{"c.md"}},
+		{name: "japanese single character", query: "書", want: []string{"c.md"}},
+		{name: "part of a term", query: "deplo", want: []string{"a.md", "b.md"}},
+		{name: "parts of terms", query: "ploy agai", want: []string{"a.md"}},
+		{name: "not found", query: "nothing", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			var got []string
+			for _, r := range newTestIndex().Search(tt.query) {
+				got = append(got, r.Path)
+			}
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestIndex_PutRemove(t *testing.T) {
+	assert := assert.New(t)
+	idx := newTestIndex()
+
+	idx.Put(&Document{Path: "a.md"}, "nothing in common")
+	assert.Equal([]string{"b.md"}, idx.Candidates("deploy"))
+
+	idx.Remove("b.md")
+	assert.Empty(idx.Candidates("deploy"))
+	assert.Equal([]string{"a.md", "c.md"}, idx.Paths())
+	assert.NotContains(idx.Postings, "sushi")
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), ".memo", "index.gob")
+	mtime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	idx := New("config")
+	idx.Put(&Document{
+		Path:    "dailymemo/2025-01-01-Wed.md",
+		ModTime: mtime,
+		Size:    10,
+		Memos:   []*models.Memo{models.NewMemo("dailymemo/2025-01-01-Wed.md", "title", "title", "content")},
+	}, "content")
+	assert.NoError(idx.Save(path))
+
+	loaded, err := Load(path, "config")
+	assert.NoError(err)
+	assert.False(loaded.Stale("dailymemo/2025-01-01-Wed.md", mtime, 10))
+	assert.True(loaded.Stale("dailymemo/2025-01-01-Wed.md", mtime.Add(time.Second), 10))
+	assert.True(loaded.Stale("dailymemo/2025-01-02-Thu.md", mtime, 10))
+	assert.Equal(idx.Memos(), loaded.Memos())
+
+	rebuilt, err := Load(path, "another config")
+	assert.NoError(err)
+	assert.Empty(rebuilt.Docs)
+	assert.Equal("another config", rebuilt.Fingerprint)
+}
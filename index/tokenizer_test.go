@@ -0,0 +1,52 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "words",
+			text: "Deploy the API, v2!",
+			want: []string{"deploy", "the", "api", "v2"},
+		},
+		{
+			name: "japanese",
+			text: "手順書",
+			want: []string{"手", "手順", "順", "順書", "書"},
+		},
+		{
+			name: "mixed",
+			text: "deployの手順",
+			want: []string{"deploy", "の", "の手", "手", "手順", "順"},
+		},
+		{
+			name: "fullwidth ascii",
+			text: "ＡＰＩ１",
+			want: []string{"api1"},
+		},
+		{
+			name: "link key",
+			text: "2024-12-31-Tue.md#something-interesting",
+			want: []string{"2024", "12", "31", "tue", "md", "something", "interesting"},
+		},
+		{
+			name: "empty",
+			text: " - ",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, Tokenize(tt.text))
+		})
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hirotoni/memo/application"
@@ -11,18 +13,24 @@ import (
 
 func init() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	tz, err := time.LoadLocation(application.TIMEZONE)
-	if err != nil {
-		panic(err)
-	}
-	time.Local = tz
 }
 
 func main() {
 	app := application.NewApp()
+	tz, err := app.Config.Location()
+	if err != nil {
+		log.Fatal(err)
+	}
+	time.Local = tz
 	app.Initialize()
 
-	cliapp := &cli.App{
+	if err := newCliApp(&app).Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newCliApp(app *application.App) *cli.App {
+	return &cli.App{
 		EnableBashCompletion: true,
 		Name:                 "memo",
 		Usage:                "A CLI tool for managing daily memo",
@@ -37,49 +45,142 @@ func main() {
 						Usage:       "specify the date to create memo: `YYYY-MM-DD`",
 						DefaultText: "today",
 					},
+					&cli.StringFlag{
+						Name:    "range",
+						Aliases: []string{"r"},
+						Usage:   "create memos of every day in the range in chronological order: `YYYY-MM-DD..YYYY-MM-DD`",
+					},
 					&cli.BoolFlag{
 						Name:    "truncate",
 						Aliases: []string{"t"},
 						Usage:   "before creating memo, truncate the file if it exists",
 					},
+					&cli.StringFlag{
+						Name:  "at",
+						Usage: "open the memo at the first empty line under the heading: e.g. `memos`, `memos > deploy`, `memos/2`",
+					},
+					&cli.BoolFlag{
+						Name:  "no-edit",
+						Usage: "do not open the editor",
+					},
 				},
 				Action: func(c *cli.Context) error {
-					var date string
+					if c.IsSet("date") && c.IsSet("range") {
+						log.Fatal("--date and --range cannot be used together")
+					}
 
-					arg := c.String("date")
-					if arg != "" {
-						d, err := time.Parse(application.SHORT_LAYOUT, arg)
+					var targetFile string
+					if arg := c.String("range"); arg != "" {
+						from, to, err := application.ParseDateRange(arg)
 						if err != nil {
-							log.Fatalf("Invalid date format: %s", arg)
+							log.Fatalf("Invalid date range: %s", arg)
 						}
-						date = d.Format(application.FULL_LAYOUT)
+						targetFile = app.GenerateMemos(from, to, c.Bool("truncate"))
 					} else {
-						date = time.Now().Format(application.FULL_LAYOUT) // default to today
+						date := time.Now() // default to today
+						if arg := c.String("date"); arg != "" {
+							d, err := time.ParseInLocation(application.SHORT_LAYOUT, arg, time.Local)
+							if err != nil {
+								log.Fatalf("Invalid date format: %s", arg)
+							}
+							date = d
+						}
+						targetFile = app.GenerateMemo(date, c.Bool("truncate"))
 					}
 
-					targetFile := app.GenerateMemo(date, c.Bool("truncate"))
 					app.WeeklyReport()
-					app.OpenEditor(targetFile)
+					if c.Bool("no-edit") {
+						return nil
+					}
+					var line int
+					if heading := c.String("at"); heading != "" {
+						line = app.LineUnderHeading(targetFile, heading)
+					}
+					app.OpenEditorAt(targetFile, line)
 					return nil
 				},
 			},
 			{
 				Name:  "weekly",
 				Usage: "generate weekly report",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-edit",
+						Usage: "do not open the editor",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					app.WeeklyReport()
+					if c.Bool("no-edit") {
+						return nil
+					}
 					app.OpenEditor(app.Config.WeeklyReportFile())
 					return nil
 				},
 			},
 			{
-				Name:  "memoarchives",
-				Usage: "generate memo archive's index",
+				Name:    "memoarchives",
+				Aliases: []string{"archives"},
+				Usage:   "generate memo archive's index",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-edit",
+						Usage: "do not open the editor",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					app.SaveMemoArchives()
+					if c.Bool("no-edit") {
+						return nil
+					}
 					app.OpenEditor(app.Config.MemoArchivesIndexFile())
 					return nil
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:      "review",
+						Usage:     "record how well you remembered a memo archive, to schedule its next review",
+						ArgsUsage: "<destination> --grade N",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "grade",
+								Usage: "0 (forgotten) to 5 (perfect)",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							grade, args := c.Int("grade"), c.Args().Slice()
+							if !c.IsSet("grade") {
+								var ok bool
+								if grade, args, ok = gradeFromArgs(args); !ok {
+									log.Fatal("--grade is required")
+								}
+							}
+							if len(args) == 0 {
+								log.Fatal("destination of memo archive is required")
+							}
+							app.ReviewMemoArchive(args[0], grade, time.Now())
+							return nil
+						},
+					},
+					{
+						Name:  "stats",
+						Usage: "show how much of memo archives has been shown in daily memos",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "item",
+								Usage: "show the history of the memo archive instead: e.g. `../memoarchives/go/notes.md#generics`",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							if destination := c.String("item"); destination != "" {
+								app.MemoArchiveHistory(destination)
+								return nil
+							}
+							app.MemoArchivesStats()
+							return nil
+						},
+					},
+				},
 			},
 			{
 				Name:  "config",
@@ -99,6 +200,101 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:      "search",
+				Usage:     "search daily memos and memo archives",
+				ArgsUsage: "<query>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "regex",
+						Aliases: []string{"e"},
+						Usage:   "treat the query as a regular expression",
+					},
+					&cli.BoolFlag{
+						Name:    "ignore-case",
+						Aliases: []string{"i"},
+						Usage:   "match case-insensitively",
+					},
+					&cli.StringFlag{
+						Name:    "heading",
+						Aliases: []string{"H"},
+						Usage:   "only search under the heading: e.g. `todos`, `memos > deploy`",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					query := c.Args().First()
+					if query == "" {
+						log.Fatal("search query is required")
+					}
+					app.Search(query, application.SearchOptions{
+						Regex:      c.Bool("regex"),
+						IgnoreCase: c.Bool("ignore-case"),
+						Heading:    c.String("heading"),
+					})
+					return nil
+				},
+			},
+			{
+				Name:  "template",
+				Usage: "manage daily memo templates",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "upgrade",
+						Usage: "insert headings missing in the templates in the order of the sections",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "only print the changes",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							app.UpgradeTemplates(c.Bool("dry-run"))
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "migrate",
+				Usage: "rename daily memos to a new filename layout and rewrite links to them",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "to-layout",
+						Usage:    "filename layout to migrate to: e.g. `2006-01-02`",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:        "to-locale",
+						Usage:       "weekday locale to migrate to: `en` or `ja`",
+						DefaultText: "current locale",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "only report what would be changed",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					app.Migrate(c.String("to-layout"), c.String("to-locale"), c.Bool("dry-run"))
+					return nil
+				},
+			},
+			{
+				Name:      "fmt",
+				Usage:     "rewrite memos in the style of the renderer config. daily memos and memo archives by default",
+				ArgsUsage: "[paths...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "only print the diff of files not formatted, and fail if any",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if !app.Format(c.Args().Slice(), c.Bool("check")) {
+						return cli.Exit("", 1)
+					}
+					return nil
+				},
+			},
 			{
 				Name:  "links",
 				Usage: "search links",
@@ -109,8 +305,26 @@ func main() {
 			},
 		},
 	}
+}
 
-	if err := cliapp.Run(os.Args); err != nil {
-		log.Fatal(err)
+// gradeFromArgs takes the grade out of the args, since flags after the destination are left in them.
+// ok is false if the grade is not given or not a number.
+func gradeFromArgs(args []string) (grade int, rest []string, ok bool) {
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || name != "grade" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, nil, false
+		}
+		grade, ok = n, true
 	}
+	return grade, rest, ok
 }
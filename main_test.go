@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hirotoni/memo/application"
+	"github.com/hirotoni/memo/configs"
+	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchivesReview(t *testing.T) {
+	destination := "../memoarchives/go.md#generics"
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "grade after destination", args: []string{destination, "--grade", "4"}},
+		{name: "grade after destination, with equal sign", args: []string{destination, "--grade=4"}},
+		{name: "grade before destination", args: []string{"--grade", "4", destination}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			t.Setenv("HOME", t.TempDir())
+			conf := configs.NewTomlConfig(t.TempDir(), 10, markdown.NewGoldmarkWrapper())
+			app := application.NewApp()
+			app.WithCustomConfig(*conf)
+			app.Initialize()
+			assert.NoError(os.WriteFile(filepath.Join(app.Config.MemoArchivesDir(), "go.md"), []byte("# Go\n\n## generics\n\nnotes\n"), 0666))
+
+			args := append([]string{"memo", "archives", "review"}, tt.args...)
+			assert.NoError(newCliApp(&app).Run(args))
+
+			schedule, err := review.Load(app.Config.ReviewFile())
+			assert.NoError(err)
+			if assert.Contains(schedule.Items, destination) {
+				assert.Equal(1, schedule.Items[destination].Repetitions)
+			}
+		})
+	}
+}
+
+func TestGradeFromArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantGrade int
+		wantRest  []string
+		wantOk    bool
+	}{
+		{name: "separate value", args: []string{"a.md", "--grade", "4"}, wantGrade: 4, wantRest: []string{"a.md"}, wantOk: true},
+		{name: "equal sign", args: []string{"a.md", "--grade=0"}, wantGrade: 0, wantRest: []string{"a.md"}, wantOk: true},
+		{name: "single dash", args: []string{"a.md", "-grade", "5"}, wantGrade: 5, wantRest: []string{"a.md"}, wantOk: true},
+		{name: "not given", args: []string{"a.md"}, wantRest: []string{"a.md"}},
+		{name: "not a number", args: []string{"a.md", "--grade", "good"}},
+		{name: "no value", args: []string{"a.md", "--grade"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grade, rest, ok := gradeFromArgs(tt.args)
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.wantGrade, grade)
+				assert.Equal(t, tt.wantRest, rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	ErrOverlappingEdits = errors.New("edits overlap")
+	ErrRegionNotFound   = errors.New("region not found")
+)
+
+// Document is a source parsed once for several queries and edits.
+// Edits are kept as replacements of ranges of the source and applied by Bytes,
+// so queries keep seeing the source as parsed and the positions of its nodes stay valid.
+type Document struct {
+	gmw    *GoldmarkWrapper
+	source []byte
+	root   ast.Node
+	edits  []edit
+}
+
+// edit replaces source[start:end] with the text
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// overlaps reports whether the edits touch the same bytes, or one inserts into the range the other replaces
+func (e edit) overlaps(o edit) bool {
+	return e.start < o.end && o.start < e.end
+}
+
+// NewDocument parses the source
+func (gmw *GoldmarkWrapper) NewDocument(source []byte) *Document {
+	return &Document{gmw: gmw, source: source, root: gmw.Parse(source)}
+}
+
+// Source returns the source as parsed, without the edits
+func (d *Document) Source() []byte {
+	return d.source
+}
+
+func (d *Document) Root() ast.Node {
+	return d.root
+}
+
+// Bytes returns the source with the edits applied. Edits at the same position are applied in the order they were made.
+func (d *Document) Bytes() []byte {
+	if len(d.edits) == 0 {
+		return d.source
+	}
+	edits := slices.Clone(d.edits)
+	slices.SortStableFunc(edits, func(a, b edit) int { return a.start - b.start })
+
+	buf := []byte{}
+	last := 0
+	for _, e := range edits {
+		buf = append(buf, d.source[last:e.start]...)
+		buf = append(buf, e.text...)
+		last = e.end
+	}
+	return append(buf, d.source[last:]...)
+}
+
+// replace records an edit. It returns ErrOverlappingEdits if the edit overlaps one already made.
+func (d *Document) replace(start, end int, text string) error {
+	e := edit{start: start, end: end, text: text}
+	for _, o := range d.edits {
+		if e.overlaps(o) {
+			return fmt.Errorf("%w: %d-%d and %d-%d", ErrOverlappingEdits, o.start, o.end, start, end)
+		}
+	}
+	d.edits = append(d.edits, e)
+	return nil
+}
+
+// splice replaces source[start:end] with the block, separated by a blank line from the text around it.
+// The result ends with a newline if the source does.
+func (d *Document) splice(start, end int, block string) error {
+	before := bytes.TrimRight(d.source[:start], "\n")
+	after := bytes.TrimLeft(d.source[end:], "\n")
+	block = strings.Trim(block, "\n")
+
+	var text string
+	if len(before) > 0 && (block != "" || len(after) > 0) {
+		text += "\n\n"
+	}
+	text += block
+	if block != "" && len(after) > 0 {
+		text += "\n\n"
+	}
+	if len(after) == 0 && bytes.HasSuffix(d.source, []byte("\n")) {
+		text += "\n"
+	}
+	return d.replace(len(before), len(d.source)-len(after), text)
+}
+
+// HeadingsByLevel returns the top level headings of the level
+func (d *Document) HeadingsByLevel(level int) []ast.Node {
+	var foundNodes []ast.Node
+	for c := d.root.FirstChild(); c != nil; c = c.NextSibling() {
+		if h, ok := c.(*ast.Heading); ok && h.Level == level {
+			foundNodes = append(foundNodes, c)
+		}
+	}
+	return foundNodes
+}
+
+// Heading returns the heading that the selector selects
+func (d *Document) Heading(heading HeadingSelector) (*ast.Heading, error) {
+	return findHeading(d.root, d.source, heading)
+}
+
+// HangingNodes returns the heading that the selector selects and the nodes under it up to the next heading of the same or upper level.
+// It returns ErrHeadingNotFound if no heading matches, and ErrAmbiguousHeading if more than one do.
+func (d *Document) HangingNodes(heading HeadingSelector) (ast.Node, []ast.Node, error) {
+	target, err := findHeading(d.root, d.source, heading)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hangingNodes []ast.Node
+	for c := target.NextSibling(); c != nil; c = c.NextSibling() {
+		if h, ok := c.(*ast.Heading); ok && h.Level <= target.Level {
+			break
+		}
+		hangingNodes = append(hangingNodes, c)
+	}
+	return target, hangingNodes, nil
+}
+
+func (d *Document) section(heading HeadingSelector) (section, error) {
+	h, err := d.Heading(heading)
+	if err != nil {
+		return section{}, err
+	}
+	start, stop, ok := NodeRange(d.source, h)
+	if !ok {
+		return section{}, fmt.Errorf("%w: %s", ErrHeadingNotFound, heading)
+	}
+
+	s := section{heading: h, start: start, bodyStart: stop, end: len(d.source)}
+	for c := h.NextSibling(); c != nil; c = c.NextSibling() {
+		if next, ok := c.(*ast.Heading); ok && next.Level <= h.Level {
+			if nextStart, _, ok := NodeRange(d.source, next); ok {
+				s.end = nextStart
+				break
+			}
+		}
+	}
+	return s, nil
+}
+
+// InsertTextAtHeadingStart inserts the text right after the heading
+func (d *Document) InsertTextAtHeadingStart(heading HeadingSelector, text string) error {
+	s, err := d.section(heading)
+	if err != nil {
+		return err
+	}
+	return d.replace(s.bodyStart, s.bodyStart, "\n\n"+text)
+}
+
+// InsertNodesAtHeadingStart inserts the nodes of another source right after the heading
+func (d *Document) InsertNodesAtHeadingStart(heading HeadingSelector, source []byte, nodes []ast.Node) error {
+	if len(nodes) == 0 {
+		_, err := d.Heading(heading)
+		return err
+	}
+	if d.gmw.EditMode == EDITMODE_SOURCE {
+		if excerpt, ok := Excerpt(source, nodes); ok {
+			return d.InsertTextAtHeadingStart(heading, string(excerpt))
+		}
+	}
+
+	s, err := d.section(heading)
+	if err != nil {
+		return err
+	}
+	tmp := new(bytes.Buffer)
+	if err := d.gmw.RenderSlice(tmp, source, nodes); err != nil {
+		return err
+	}
+	return d.replace(s.bodyStart, s.bodyStart, tmp.String())
+}
+
+// ReplaceHangingNodes replaces the content of the section with the nodes of another source
+func (d *Document) ReplaceHangingNodes(heading HeadingSelector, source []byte, nodes []ast.Node) error {
+	tmp := new(bytes.Buffer)
+	if excerpt, ok := Excerpt(source, nodes); d.gmw.EditMode == EDITMODE_SOURCE && ok {
+		tmp.Write(excerpt)
+	} else if err := d.gmw.RenderSlice(tmp, source, nodes); err != nil {
+		return err
+	}
+	return d.ReplaceSection(heading, tmp.String())
+}
+
+// ReplaceSection replaces the content of the section, including its subsections, with the text
+func (d *Document) ReplaceSection(heading HeadingSelector, text string) error {
+	s, err := d.section(heading)
+	if err != nil {
+		return err
+	}
+	return d.splice(s.bodyStart, s.end, text)
+}
+
+// DeleteSection deletes the heading and its content, including its subsections
+func (d *Document) DeleteSection(heading HeadingSelector) error {
+	s, err := d.section(heading)
+	if err != nil {
+		return err
+	}
+	return d.splice(s.start, s.end, "")
+}
+
+// AppendToSection appends the text to the end of the section, after its subsections
+func (d *Document) AppendToSection(heading HeadingSelector, text string) error {
+	s, err := d.section(heading)
+	if err != nil {
+		return err
+	}
+	return d.splice(s.end, s.end, text)
+}
+
+// ReplaceRegion replaces the content between the begin and end markers with the text.
+// The markers are html comments on lines of their own, such as <!-- begin -->, and the first pair in the document is taken.
+func (d *Document) ReplaceRegion(begin, end string, text string) error {
+	start := -1
+	for c := d.root.FirstChild(); c != nil; c = c.NextSibling() {
+		if _, ok := c.(*ast.HTMLBlock); !ok {
+			continue
+		}
+		s, e, ok := NodeRange(d.source, c)
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(string(d.source[s:e])) {
+		case begin:
+			if start < 0 {
+				start = e
+			}
+		case end:
+			if start >= 0 {
+				return d.splice(start, s, text)
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s %s", ErrRegionNotFound, begin, end)
+}
@@ -0,0 +1,169 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	source := "# memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n### deploy\n\nnotes\n"
+
+	tests := []struct {
+		name    string
+		edit    func(d *Document) error
+		want    string
+		wantErr error
+	}{
+		{
+			name: "no edits",
+			edit: func(d *Document) error { return nil },
+			want: source,
+		},
+		{
+			name: "edits in several sections",
+			edit: func(d *Document) error {
+				if err := d.InsertTextAtHeadingStart(NewHeading(2, "todos"), "- [ ] new"); err != nil {
+					return err
+				}
+				if err := d.ReplaceSection(NewHeading(3, "deploy"), "released"); err != nil {
+					return err
+				}
+				return d.InsertTextAtHeadingStart(NewHeading(1, "memo"), "2025-01-01")
+			},
+			want: "# memo\n\n2025-01-01\n\n## todos\n\n- [ ] new\n\n- [ ] todo\n\n## memos\n\n### deploy\n\nreleased\n",
+		},
+		{
+			name: "inserts at the same position in order",
+			edit: func(d *Document) error {
+				if err := d.InsertTextAtHeadingStart(NewHeading(2, "todos"), "- [ ] first"); err != nil {
+					return err
+				}
+				return d.InsertTextAtHeadingStart(NewHeading(2, "todos"), "- [ ] second")
+			},
+			want: "# memo\n\n## todos\n\n- [ ] first\n\n- [ ] second\n\n- [ ] todo\n\n## memos\n\n### deploy\n\nnotes\n",
+		},
+		{
+			name: "queries see the source as parsed",
+			edit: func(d *Document) error {
+				if err := d.ReplaceSection(NewHeading(2, "memos"), "## links"); err != nil {
+					return err
+				}
+				_, err := d.Heading(NewHeading(2, "links"))
+				return err
+			},
+			wantErr: ErrHeadingNotFound,
+		},
+		{
+			name: "overlapping edits",
+			edit: func(d *Document) error {
+				if err := d.ReplaceSection(NewHeading(2, "memos"), "new memo"); err != nil {
+					return err
+				}
+				return d.InsertTextAtHeadingStart(NewHeading(3, "deploy"), "more notes")
+			},
+			wantErr: ErrOverlappingEdits,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			d := NewGoldmarkWrapper().NewDocument([]byte(source))
+			err := tt.edit(d)
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(d.Bytes()))
+			assert.Equal(source, string(d.Source()))
+		})
+	}
+}
+
+// dailymemoSource returns a daily memo with the number of memos, as they grow over years
+func dailymemoSource(memos int) []byte {
+	var sb strings.Builder
+	sb.WriteString("# daily memo\n\n## date\n\n## todos\n\n- [ ] open\n- [x] done\n\n## wanttodos\n\n- [ ] someday\n\n## memo archives\n\n## memos\n")
+	for i := range memos {
+		fmt.Fprintf(&sb, "\n### memo %d\n\nsome *notes* with a [link](https://example.com)\n\n```go\nfunc main() {}\n```\n", i)
+	}
+	return []byte(sb.String())
+}
+
+// BenchmarkEdits compares editing a memo section by section through the wrapper, which parses the source on every edit,
+// with editing it through a document parsed once
+func BenchmarkEdits(b *testing.B) {
+	source := dailymemoSource(50)
+	headings := []Heading{NewHeading(2, "date"), NewHeading(2, "todos"), NewHeading(2, "wanttodos"), NewHeading(2, "memo archives")}
+
+	b.Run("wrapper", func(b *testing.B) {
+		gmw := NewGoldmarkWrapper()
+		for range b.N {
+			content := source
+			for _, h := range headings {
+				var err error
+				if content, err = gmw.InsertTextAtHeadingStart(content, h, "- item"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("document", func(b *testing.B) {
+		gmw := NewGoldmarkWrapper()
+		for range b.N {
+			d := gmw.NewDocument(source)
+			for _, h := range headings {
+				if err := d.InsertTextAtHeadingStart(h, "- item"); err != nil {
+					b.Fatal(err)
+				}
+			}
+			d.Bytes()
+		}
+	})
+}
+
+func TestDocument_ReplaceRegion(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "between markers",
+			source: "# index\n\nnotes\n\n<!-- begin -->\n- old\n<!-- end -->\n\n## mine\n",
+			want:   "# index\n\nnotes\n\n<!-- begin -->\n\n- new\n\n<!-- end -->\n\n## mine\n",
+		},
+		{
+			name:   "empty region",
+			source: "<!-- begin -->\n<!-- end -->\n",
+			want:   "<!-- begin -->\n\n- new\n\n<!-- end -->\n",
+		},
+		{
+			name:    "markers in a code block",
+			source:  "```\n<!-- begin -->\n<!-- end -->\n```\n",
+			wantErr: ErrRegionNotFound,
+		},
+		{
+			name:    "end before begin",
+			source:  "<!-- end -->\n\n<!-- begin -->\n",
+			wantErr: ErrRegionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			d := NewGoldmarkWrapper().NewDocument([]byte(tt.source))
+			err := d.ReplaceRegion("<!-- begin -->", "<!-- end -->", "- new")
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(d.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+)
+
+// Excerpt returns the source of the sibling block nodes as written, from the first line of the first node to the last line of the last one.
+// Nodes removed from the tree, such as list items pruned from a list, are left out along with the lines that belonged to them.
+// It returns false if a node cannot be located in the source.
+func Excerpt(source []byte, nodes []ast.Node) ([]byte, bool) {
+	var buf bytes.Buffer
+	for i, n := range nodes {
+		if !writeExcerpt(&buf, source, n) {
+			return nil, false
+		}
+		if i < len(nodes)-1 {
+			_, stop, _ := NodeRange(source, n)
+			start, _, ok := NodeRange(source, nodes[i+1])
+			if !ok {
+				return nil, false
+			}
+			buf.Write(gapBetween(source, stop, start))
+		}
+	}
+	return buf.Bytes(), true
+}
+
+// writeExcerpt writes the source of the node, skipping the lines of children removed from the tree
+func writeExcerpt(buf *bytes.Buffer, source []byte, n ast.Node) bool {
+	if !isContainer(n) {
+		start, stop, ok := NodeRange(source, n)
+		if !ok {
+			return false
+		}
+		buf.Write(source[start:stop])
+		return true
+	}
+
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if !writeExcerpt(buf, source, c) {
+			return false
+		}
+		if next := c.NextSibling(); next != nil {
+			_, stop, _ := NodeRange(source, c)
+			start, _, ok := NodeRange(source, next)
+			if !ok {
+				return false
+			}
+			buf.Write(gapBetween(source, stop, start))
+		}
+	}
+	return true
+}
+
+// NodeRange returns the range of the whole lines of the block node in the source, excluding the newline at the end.
+// The range of a container such as a list follows its children in the tree, so it shrinks when children are removed.
+// It returns false if the node has no position in the source. e.g. an empty list item
+func NodeRange(source []byte, n ast.Node) (int, int, bool) {
+	if n == nil {
+		return 0, 0, false
+	}
+	if isContainer(n) {
+		start, _, ok := NodeRange(source, n.FirstChild())
+		if !ok {
+			return 0, 0, false
+		}
+		_, stop, ok := NodeRange(source, n.LastChild())
+		if !ok {
+			return 0, 0, false
+		}
+		return start, stop, true
+	}
+
+	switch n := n.(type) {
+	case *extast.Table:
+		// the cells are the only nodes with positions
+		start, stop := -1, -1
+		_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+			if entering && c.Type() == ast.TypeBlock && c.Lines().Len() > 0 {
+				if start < 0 {
+					start = c.Lines().At(0).Start
+				}
+				stop = c.Lines().At(c.Lines().Len() - 1).Stop
+			}
+			return ast.WalkContinue, nil
+		})
+		if start < 0 {
+			return 0, 0, false
+		}
+		start, stop = lineStart(source, start), lineEnd(source, max(stop-1, start))
+		if n.ChildCount() == 1 {
+			// the delimiter row follows the header
+			stop = nextLineEnd(source, stop)
+		}
+		return start, stop, true
+	case *ast.FencedCodeBlock:
+		// the fences are not a part of the lines
+		var start, stop int
+		switch {
+		case n.Info != nil:
+			start = lineStart(source, n.Info.Segment.Start)
+			stop = lineEnd(source, n.Info.Segment.Start)
+		case n.Lines().Len() > 0:
+			start = lineStart(source, max(lineStart(source, n.Lines().At(0).Start)-1, 0))
+			stop = start
+		default:
+			return 0, 0, false
+		}
+		if n.Lines().Len() > 0 {
+			last := n.Lines().At(n.Lines().Len() - 1)
+			stop = lineEnd(source, max(last.Stop-1, last.Start))
+		}
+		if next := nextLineEnd(source, stop); next > stop {
+			line := bytes.TrimLeft(source[stop+1:next], " \t>")
+			if bytes.HasPrefix(line, []byte("```")) || bytes.HasPrefix(line, []byte("~~~")) {
+				stop = next
+			}
+		}
+		return start, stop, true
+	}
+
+	if n.Lines().Len() == 0 {
+		// e.g. thematic breaks, which take the first line after the previous block
+		prev := n.PreviousSibling()
+		if prev == nil {
+			return 0, 0, false
+		}
+		_, pos, ok := NodeRange(source, prev)
+		if !ok {
+			return 0, 0, false
+		}
+		for pos < len(source) {
+			start, stop := pos+1, lineEnd(source, pos+1)
+			if len(bytes.Trim(source[start:stop], " \t>\r")) > 0 {
+				return start, stop, true
+			}
+			pos = stop
+		}
+		return 0, 0, false
+	}
+
+	first, last := n.Lines().At(0), n.Lines().At(n.Lines().Len()-1)
+	start := lineStart(source, first.Start)
+	stop := lineEnd(source, max(last.Stop-1, last.Start))
+	switch n := n.(type) {
+	case *ast.HTMLBlock:
+		if n.HasClosure() {
+			stop = lineEnd(source, max(n.ClosureLine.Stop-1, n.ClosureLine.Start))
+		}
+	case *ast.Heading:
+		if !bytes.HasPrefix(bytes.TrimLeft(source[start:stop], " \t>"), []byte("#")) {
+			// the underline of a setext heading
+			stop = nextLineEnd(source, stop)
+		}
+	}
+	return start, stop, true
+}
+
+// isContainer reports whether the node consists of block children only, whose lines make up the lines of the node
+func isContainer(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.List, *ast.ListItem, *ast.Blockquote:
+		return n.FirstChild() != nil
+	}
+	return false
+}
+
+// gapBetween returns the blank lines between two blocks, up to the lines of removed blocks if any.
+// It starts with the newline ending the first block.
+func gapBetween(source []byte, stop, start int) []byte {
+	if start <= stop {
+		return []byte("\n")
+	}
+	gap := source[stop:start]
+	for pos := 1; pos < len(gap); {
+		end := bytes.IndexByte(gap[pos:], '\n')
+		if end < 0 {
+			end = len(gap) - pos
+		}
+		if len(bytes.Trim(gap[pos:pos+end], " \t>\r")) > 0 {
+			return gap[:pos]
+		}
+		pos += end + 1
+	}
+	return gap
+}
+
+// lineEnd returns the position of the newline ending the line that contains pos, or the end of the source
+func lineEnd(source []byte, pos int) int {
+	if i := bytes.IndexByte(source[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(source)
+}
+
+// nextLineEnd returns the end of the line following the line ending at stop, or stop if it is the last line
+func nextLineEnd(source []byte, stop int) int {
+	if stop >= len(source) {
+		return stop
+	}
+	return lineEnd(source, stop+1)
+}
@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark/ast"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		prune func([]ast.Node) []ast.Node
+		want  string
+	}{
+		{
+			name: "as written",
+			body: "* item  \n+ other marker\n\n\n1) ordered\n\nsetext\n------\n\n***\n\n```go\ncode\n```\n\n| a |\n|---|\n\n<!--\ncomment\n-->\n\n> quote\nlazy",
+			want: "* item  \n+ other marker\n\n\n1) ordered\n\nsetext\n------\n\n***\n\n```go\ncode\n```\n\n| a |\n|---|\n\n<!--\ncomment\n-->\n\n> quote\nlazy",
+		},
+		{
+			name:  "pruned checked tasks",
+			body:  "* [ ] open\n* [x] done\n\n  note\n* [ ] open again\n    * [x] done sub task\n    * [ ] open sub task",
+			prune: func(nodes []ast.Node) []ast.Node { kept, _ := PruneCheckedTasks(nodes); return kept },
+			want:  "* [ ] open\n* [ ] open again\n    * [ ] open sub task",
+		},
+		{
+			name:  "pruned open tasks in loose list",
+			body:  "- [ ] open\n\n- [x] done\n\n- [ ] open again\n\nparagraph",
+			prune: func(nodes []ast.Node) []ast.Node { kept, _ := PruneOpenTasks(nodes); return kept },
+			want:  "- [x] done",
+		},
+		{
+			name:  "pruned whole nodes",
+			body:  "- [x] done\n\nparagraph\n\n- [ ] open",
+			prune: func(nodes []ast.Node) []ast.Node { kept, _ := PruneCheckedTasks(nodes); return kept },
+			want:  "paragraph\n\n- [ ] open",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			gmw := NewGoldmarkWrapper()
+			source := []byte("# memos\n\n" + tt.body + "\n")
+			_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(source, NewHeading(1, "memos"))
+			if tt.prune != nil {
+				nodes = tt.prune(nodes)
+			}
+
+			got, ok := Excerpt(source, nodes)
+			assert.True(ok)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
+
+// markdownBody is a random sequence of blocks separated by blank lines
+type markdownBody string
+
+var bodyBlocks = []string{
+	"paragraph with trailing spaces  \nand a hard break",
+	"lazy *paragraph*\ncontinued _here_",
+	"- tight\n- list",
+	"* star\n* list\n\n* made loose",
+	"+ [ ] open task\n+ [x] done task\n  + [ ] nested task",
+	"1. ordered\n2. list\n   - nested bullet",
+	"3) paren\n4) ordered",
+	"> quote\n> > nested quote\n\n> second quote",
+	"```go\nfunc main() {}\n\n```",
+	"~~~~\n~~~ not a fence\n~~~~",
+	"| a | b |\n|:-|--:|\n| 1 | 2 |",
+	"<details>\n<summary>folded</summary>",
+	"<!--\nhidden\n-->",
+	"### sub heading ###",
+	"---",
+	"- item\n\n  ___\n\n  continued",
+	"![image](<a b.png> 'title') and <https://example.com>",
+}
+
+func (markdownBody) Generate(r *rand.Rand, _ int) reflect.Value {
+	var sb strings.Builder
+	for i := range r.Intn(8) + 1 {
+		if i > 0 {
+			sb.WriteString(strings.Repeat("\n", r.Intn(2)+2))
+		}
+		sb.WriteString(bodyBlocks[r.Intn(len(bodyBlocks))])
+	}
+	return reflect.ValueOf(markdownBody(sb.String()))
+}
+
+func TestExcerpt_lossless(t *testing.T) {
+	gmw := NewGoldmarkWrapper()
+
+	extract := func(body markdownBody) bool {
+		source := []byte("## memos\n\n" + string(body) + "\n\n## next\n")
+		_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(source, NewHeading(2, "memos"))
+		got, ok := Excerpt(source, nodes)
+		return ok && string(got) == string(body)
+	}
+	if err := quick.Check(extract, nil); err != nil {
+		t.Error(err)
+	}
+
+	insert := func(body markdownBody) bool {
+		source := []byte("## memos\n\n" + string(body) + "\n")
+		_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(source, NewHeading(2, "memos"))
+		got, err := gmw.InsertNodesAtHeadingStart([]byte("# today\n\n## memos\n\n## todos\n"), NewHeading(2, "memos"), source, nodes)
+		return err == nil && string(got) == "# today\n\n## memos\n\n"+string(body)+"\n\n## todos\n"
+	}
+	if err := quick.Check(insert, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoldmarkWrapper_InsertNodesAtHeadingStart(t *testing.T) {
+	source := []byte("## todos\n\n* [ ] task  \n* [ ] another\n")
+	target := "## todos\n"
+
+	tests := []struct {
+		name string
+		mode EditMode
+		want string
+	}{
+		{name: "source", mode: EDITMODE_SOURCE, want: "## todos\n\n* [ ] task  \n* [ ] another\n"},
+		{name: "render", mode: EDITMODE_RENDER, want: "## todos\n\n* [ ] task\n* [ ] another\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gmw := NewGoldmarkWrapper(WithEditMode(tt.mode))
+			_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(source, NewHeading(2, "todos"))
+			got, err := gmw.InsertNodesAtHeadingStart([]byte(target), NewHeading(2, "todos"), source, nodes)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
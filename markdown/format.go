@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	ErrUnsupportedNode = errors.New("the renderer cannot write the markdown")
+	ErrNotRoundTrip    = errors.New("the formatted markdown means something else")
+)
+
+// htmlMarkdown converts markdown to html, to compare what two sources mean
+var htmlMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Format rewrites the source with the markdown renderer, ending with a newline.
+// It returns ErrUnsupportedNode if the source has something the renderer cannot write, such as link reference definitions,
+// and ErrNotRoundTrip if the result would be converted to html differently from the source.
+func (gmw *GoldmarkWrapper) Format(source []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(source)) == 0 {
+		return source, nil
+	}
+
+	ctx := parser.NewContext()
+	doc := gmw.Goldmark.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+	if len(ctx.References()) > 0 {
+		// the definitions are not in the tree, so they would be lost
+		return nil, fmt.Errorf("%w: link reference definitions", ErrUnsupportedNode)
+	}
+	r := myrenderer.NewMarkdownRenderer()
+	var unsupported ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && !r.CanRender(n.Kind()) {
+			unsupported = n
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if unsupported != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedNode, unsupported.Kind())
+	}
+
+	var buf bytes.Buffer
+	if err := gmw.Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+	formatted := bytes.TrimRight(bytes.TrimLeft(buf.Bytes(), "\r\n"), "\r\n")
+	if myrenderer.LineEndingOf(gmw.rendererOptions...) == myrenderer.LINEENDING_CRLF {
+		formatted = append(formatted, "\r\n"...)
+	} else {
+		formatted = append(formatted, '\n')
+	}
+
+	want, err := htmlOf(source)
+	if err != nil {
+		return nil, err
+	}
+	got, err := htmlOf(formatted)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(want, got) {
+		return nil, ErrNotRoundTrip
+	}
+	return formatted, nil
+}
+
+// htmlOf converts the markdown to html regardless of the line ending
+func htmlOf(source []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlMarkdown.Convert(bytes.ReplaceAll(source, []byte("\r\n"), []byte("\n")), &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"os"
+	"testing"
+
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldmarkWrapper_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []GoldmarkWrapperOption
+		source  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "formatted",
+			source: "# memo\n\n- a\n- b\n",
+			want:   "# memo\n\n- a\n- b\n",
+		},
+		{
+			name:   "normalized",
+			source: "memo\n====\n\n\n\n1. a\n1. b\n\n***\n| a | b |\n|---|---|\n| 1 |",
+			want:   "# memo\n\n1. a\n2. b\n\n---\n| a   | b   |\n| --- | --- |\n| 1   |     |\n",
+		},
+		{
+			name:   "configured style",
+			opts:   []GoldmarkWrapperOption{WithRendererOptions(myrenderer.WithBulletMarker('-'), myrenderer.WithEmphasisMarker('_'))},
+			source: "* *a*\n* b\n",
+			want:   "- _a_\n- b\n",
+		},
+		{
+			name:   "heading after a paragraph",
+			source: "para\n# heading\n",
+			want:   "para\n# heading\n",
+		},
+		{
+			name:   "heading after a list",
+			source: "- a\n- b\n# h\n",
+			want:   "- a\n- b\n# h\n",
+		},
+		{
+			name:   "backtick in code span",
+			source: "use `` a`b `` here\n",
+			want:   "use ``a`b`` here\n",
+		},
+		{
+			name:   "crlf of a single line",
+			opts:   []GoldmarkWrapperOption{WithRendererOptions(myrenderer.WithLineEnding(myrenderer.LINEENDING_CRLF))},
+			source: "memo\n",
+			want:   "memo\r\n",
+		},
+		{
+			name:   "empty",
+			source: "\n",
+			want:   "\n",
+		},
+		{
+			name:    "link reference definitions",
+			source:  "[memo][1]\n\n[1]: memo.md\n",
+			wantErr: ErrUnsupportedNode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := NewGoldmarkWrapper(tt.opts...).Format([]byte(tt.source))
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
+
+func TestGoldmarkWrapper_Format_idempotent(t *testing.T) {
+	assert := assert.New(t)
+	source, err := os.ReadFile("./testdata/sample.md")
+	assert.NoError(err)
+
+	gmw := NewGoldmarkWrapper()
+	once, err := gmw.Format(source)
+	assert.NoError(err)
+	twice, err := gmw.Format(once)
+	assert.NoError(err)
+	assert.Equal(string(once), string(twice))
+}
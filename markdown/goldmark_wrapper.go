@@ -3,6 +3,7 @@ package markdown
 import (
 	"bytes"
 	"io"
+	"maps"
 	"slices"
 	"strings"
 
@@ -17,29 +18,97 @@ import (
 )
 
 type Heading struct {
-	Level int
-	Text  string
+	Level     int
+	Text      string
+	Match     MatchMode
+	SlugStyle SlugStyle // the style of the slugs MATCHMODE_SLUG compares. defaults to SLUGSTYLE_GITHUB
 }
 
 func NewHeading(level int, text string) Heading {
 	return Heading{Level: level, Text: text}
 }
 
+// MatchMode is how the text of a heading is compared with headings in documents
+type MatchMode int
+
+const (
+	MATCHMODE_SUBSTRING MatchMode = iota // the heading contains the text. an exact match wins over the others
+	MATCHMODE_EXACT                      // the heading is the text
+	MATCHMODE_SLUG                       // the heading has the same slug as the text in the slug style. e.g. "deploy-notes" for "Deploy notes"
+)
+
+// Matches reports whether the text of a heading in a document matches the heading regardless of the level
+func (h Heading) Matches(text string) bool {
+	text = strings.TrimSpace(text)
+	switch h.Match {
+	case MATCHMODE_EXACT:
+		return text == h.Text
+	case MATCHMODE_SLUG:
+		slugify, ok := slugFuncs[h.SlugStyle]
+		if !ok {
+			slugify = slugFuncs[SLUGSTYLE_GITHUB]
+		}
+		return slugify(text) == slugify(h.Text)
+	default:
+		return strings.Contains(text, h.Text)
+	}
+}
+
+func (h Heading) String() string {
+	return BuildHeading(h.Level, h.Text)
+}
+
+// EditMode is how nodes are written when they are inserted into another document
+type EditMode string
+
+const (
+	EDITMODE_SOURCE EditMode = "source" // copy the source of the nodes as written
+	EDITMODE_RENDER EditMode = "render" // render the nodes with the markdown renderer
+)
+
 type GoldmarkWrapper struct {
 	Goldmark goldmark.Markdown
+	EditMode EditMode
+
+	rendererOptions []renderer.Option
 }
 
-func NewGoldmarkWrapper() *GoldmarkWrapper {
-	return &GoldmarkWrapper{
-		Goldmark: goldmark.New(
-			goldmark.WithExtensions(extension.GFM),
-			goldmark.WithRendererOptions(
+type GoldmarkWrapperOption func(*GoldmarkWrapper)
+
+// WithEditMode sets the edit mode. An empty mode keeps the default, EDITMODE_SOURCE.
+func WithEditMode(mode EditMode) GoldmarkWrapperOption {
+	return func(gmw *GoldmarkWrapper) {
+		if mode != "" {
+			gmw.EditMode = mode
+		}
+	}
+}
+
+// WithRendererOptions sets options of the markdown renderer. e.g. myrenderer.WithBulletMarker('-')
+func WithRendererOptions(opts ...renderer.Option) GoldmarkWrapperOption {
+	return func(gmw *GoldmarkWrapper) {
+		gmw.rendererOptions = append(gmw.rendererOptions, opts...)
+	}
+}
+
+func NewGoldmarkWrapper(opts ...GoldmarkWrapperOption) *GoldmarkWrapper {
+	gmw := &GoldmarkWrapper{
+		EditMode: EDITMODE_SOURCE,
+	}
+	for _, opt := range opts {
+		opt(gmw)
+	}
+	gmw.Goldmark = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			append([]renderer.Option{
 				renderer.WithNodeRenderers(
 					util.Prioritized(myrenderer.NewMarkdownRenderer(), 1),
 				),
-			),
+			}, gmw.rendererOptions...)...,
 		),
-	}
+	)
+	return gmw
 }
 
 func (gmw *GoldmarkWrapper) Parse(source []byte) ast.Node {
@@ -67,152 +136,174 @@ func (gmw *GoldmarkWrapper) RenderSlice(writer io.Writer, input []byte, nodes []
 }
 
 func (gmw *GoldmarkWrapper) GetHeadingNodesByLevel(source []byte, level int) (ast.Node, []ast.Node) {
-	doc := gmw.Parse(source)
-	var foundNodes []ast.Node
-	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
-		if c.Kind() == ast.KindHeading {
-			levelMatched := c.(*ast.Heading).Level == level
-			if levelMatched {
-				foundNodes = append(foundNodes, c)
-			}
-		}
+	d := gmw.NewDocument(source)
+	return d.Root(), d.HeadingsByLevel(level)
+}
+
+// GetHeadingNode returns the document and the heading in it that matches the heading
+func (gmw *GoldmarkWrapper) GetHeadingNode(source []byte, heading HeadingSelector) (ast.Node, ast.Node, error) {
+	d := gmw.NewDocument(source)
+	h, err := d.Heading(heading)
+	if err != nil {
+		return d.Root(), nil, err
 	}
-	return doc, foundNodes
+	return d.Root(), h, nil
 }
 
-func (gmw *GoldmarkWrapper) GetHeadingNode(source []byte, heading Heading) (ast.Node, ast.Node) {
-	doc := gmw.Parse(source)
+// HeadingLine returns the 1-based line number of the heading that the selector selects
+func (gmw *GoldmarkWrapper) HeadingLine(source []byte, heading HeadingSelector) (int, error) {
+	h, err := findHeading(gmw.Parse(source), source, heading)
+	if err != nil {
+		return 0, err
+	}
+	return bytes.Count(source[:h.Lines().At(0).Start], []byte("\n")) + 1, nil
+}
 
-	var foundNode ast.Node
-	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
-		if c.Kind() == ast.KindHeading {
-			levelMatched := c.(*ast.Heading).Level == heading.Level
-			textMatched := strings.Contains(string(c.Text(source)), heading.Text)
-			if levelMatched && textMatched {
-				foundNode = c
+// LinesInSections reports for each line of the source whether it is in one of the sections that the selector selects
+func (gmw *GoldmarkWrapper) LinesInSections(source []byte, heading HeadingSelector) []bool {
+	doc := gmw.Parse(source)
+	lines := make([]bool, bytes.Count(source, []byte("\n"))+1)
+	for _, h := range heading.SelectHeadings(doc, source) {
+		first := bytes.Count(source[:h.Lines().At(0).Start], []byte("\n"))
+		last := len(lines)
+		for c := h.NextSibling(); c != nil; c = c.NextSibling() {
+			if next, ok := c.(*ast.Heading); ok && next.Level <= h.Level && next.Lines().Len() > 0 {
+				last = bytes.Count(source[:next.Lines().At(0).Start], []byte("\n"))
 				break
 			}
 		}
+		for i := first; i < last; i++ {
+			lines[i] = true
+		}
 	}
-	return doc, foundNode
+	return lines
 }
 
-// FindHeadingAndGetHangingNodes finds a heading that matches given text and level, then returns the found heading and hanging nodes of the heading
-func (gmw *GoldmarkWrapper) FindHeadingAndGetHangingNodes(source []byte, heading Heading) (ast.Node, []ast.Node) {
+// HeadingPathsByLine returns the texts of the headings enclosing each line of the source, outermost first. e.g. ["memos", "deploy notes"]
+func (gmw *GoldmarkWrapper) HeadingPathsByLine(source []byte) [][]string {
 	doc := gmw.Parse(source)
 
-	const (
-		modeSearching = iota
-		modeExiting
-	)
+	var levels []int
+	var texts []string
 
-	var foundHeading ast.Node
-	var hangingNodes []ast.Node
-	var mode = modeSearching
-
-loop:
+	paths := make([][]string, bytes.Count(source, []byte("\n"))+1)
+	line := 0
 	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
-		if c.Kind() == ast.KindHeading {
-			switch mode {
-			case modeSearching:
-				levelMatched := c.(*ast.Heading).Level == heading.Level
-				textMatched := strings.Contains(string(c.Text(source)), heading.Text)
-				if levelMatched && textMatched {
-					foundHeading = c
-					mode = modeExiting
-				}
-			case modeExiting:
-				if c.(*ast.Heading).Level <= heading.Level {
-					break loop
-				} else {
-					hangingNodes = append(hangingNodes, c)
-				}
-			}
-		} else {
-			switch mode {
-			case modeSearching:
-				continue
-			case modeExiting:
-				hangingNodes = append(hangingNodes, c)
-			}
+		h, ok := c.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		headingLine := bytes.Count(source[:h.Lines().At(0).Start], []byte("\n"))
+		for ; line < headingLine; line++ {
+			paths[line] = slices.Clone(texts)
 		}
+
+		for len(levels) > 0 && levels[len(levels)-1] >= h.Level {
+			levels = levels[:len(levels)-1]
+			texts = texts[:len(texts)-1]
+		}
+		levels = append(levels, h.Level)
+		texts = append(texts, string(h.Text(source)))
+	}
+	for ; line < len(paths); line++ {
+		paths[line] = slices.Clone(texts)
 	}
+	return paths
+}
 
-	return foundHeading, hangingNodes
+// FindHeadingAndGetHangingNodes finds a heading that matches given text and level, then returns the found heading and hanging nodes of the heading.
+// It returns ErrHeadingNotFound if no heading matches, and ErrAmbiguousHeading if more than one do.
+func (gmw *GoldmarkWrapper) FindHeadingAndGetHangingNodes(source []byte, heading HeadingSelector) (ast.Node, []ast.Node, error) {
+	return gmw.NewDocument(source).HangingNodes(heading)
 }
 
 // InsertNodesAtHeadingStart inserts nodes to document at target position, and returns updated byte array of document as the result of the insert operation
-func (gmw *GoldmarkWrapper) InsertNodesAtHeadingStart(sourceSelf []byte, targetHeading Heading, sourceNodesToInsert []byte, nodesToInsert []ast.Node) []byte {
-	// insert from tail nodes
-	slices.Reverse(nodesToInsert)
-
-	doc, targetNode := gmw.GetHeadingNode(sourceSelf, targetHeading)
-
-	// for compaitibility
-	if targetNode == nil {
-		// TODO return info that target heading is not found
-		return sourceSelf
+func (gmw *GoldmarkWrapper) InsertNodesAtHeadingStart(sourceSelf []byte, targetHeading HeadingSelector, sourceNodesToInsert []byte, nodesToInsert []ast.Node) ([]byte, error) {
+	d := gmw.NewDocument(sourceSelf)
+	if err := d.InsertNodesAtHeadingStart(targetHeading, sourceNodesToInsert, nodesToInsert); err != nil {
+		return nil, err
 	}
+	return d.Bytes(), nil
+}
 
-	for _, n := range nodesToInsert {
-		doc.InsertAfter(doc, targetNode, n)
-		s := targetNode.Lines().At(0) // TODO error handling
-
-		tmp := new(bytes.Buffer)
-		gmw.Render(tmp, sourceNodesToInsert, n)
-
-		buf := []byte{}
-		buf = append(buf, sourceSelf[:s.Stop]...)
-		buf = append(buf, tmp.Bytes()...)
-		buf = append(buf, sourceSelf[s.Stop:]...)
-		sourceSelf = buf
+// InsertTextAtHeadingStart inserts text right after the heading, and returns updated byte array of document as the result of the insert operation
+func (gmw *GoldmarkWrapper) InsertTextAtHeadingStart(sourceSelf []byte, targetHeading HeadingSelector, text string) ([]byte, error) {
+	d := gmw.NewDocument(sourceSelf)
+	if err := d.InsertTextAtHeadingStart(targetHeading, text); err != nil {
+		return nil, err
 	}
-
-	return sourceSelf
+	return d.Bytes(), nil
 }
 
-// InsertTextAfter inserts text to document at target position, and returns updated byte array of document as the result of the insert operation
-func (gmw *GoldmarkWrapper) InsertTextAtHeadingStart(sourceSelf []byte, targetHeading Heading, text string) []byte {
-	_, targetHeadingNode := gmw.GetHeadingNode(sourceSelf, targetHeading)
-
-	// for compaitibility
-	if targetHeadingNode == nil {
-		// TODO return info that target heading is not found
-		return sourceSelf
+// ReplaceHangingNodes replaces hanging nodes of the target heading with nodes from another source, and returns updated byte array of document as the result of the replace operation
+func (gmw *GoldmarkWrapper) ReplaceHangingNodes(sourceSelf []byte, targetHeading HeadingSelector, sourceNodes []byte, nodes []ast.Node) ([]byte, error) {
+	d := gmw.NewDocument(sourceSelf)
+	if err := d.ReplaceHangingNodes(targetHeading, sourceNodes, nodes); err != nil {
+		return nil, err
 	}
-
-	s := targetHeadingNode.Lines().At(0)
-
-	buf := []byte{}
-	buf = append(buf, sourceSelf[:s.Stop]...)
-	buf = append(buf, []byte("\n\n"+text)...)
-	buf = append(buf, sourceSelf[s.Stop:]...)
-	sourceSelf = buf
-
-	return sourceSelf
+	return d.Bytes(), nil
 }
 
-// InsertTextAfter inserts text to document at target position, and returns updated byte array of document as the result of the insert operation
-func (gmw *GoldmarkWrapper) InsertTextAtHeadingEnd(sourceSelf []byte, targetHeading Heading, text string) []byte {
-	foundHeading, children := gmw.FindHeadingAndGetHangingNodes(sourceSelf, targetHeading)
-	if foundHeading == nil {
-		// TODO return info that target heading is not found
-		return sourceSelf
+// InsertMissingHeadings inserts the headings missing in the source, keeping the order of the headings.
+// A missing heading is inserted right before the next heading present in the source, or at the end.
+// Headings match when both the level and the text are the same.
+func (gmw *GoldmarkWrapper) InsertMissingHeadings(source []byte, headings []Heading) []byte {
+	doc := gmw.Parse(source)
+
+	positions := make([]int, len(headings)) // line start of each heading in the source, or -1 if missing
+	for i, h := range headings {
+		positions[i] = -1
+		for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+			hn, ok := c.(*ast.Heading)
+			if ok && hn.Level == h.Level && hn.Lines().Len() > 0 && strings.TrimSpace(string(hn.Text(source))) == h.Text {
+				positions[i] = lineStart(source, hn.Lines().At(0).Start)
+				break
+			}
+		}
 	}
 
-	// determine the last position of the found children
-	var last int
-	if len(children) > 0 {
-		last = children[len(children)-1].Lines().At(0).Stop
-	} else {
-		last = foundHeading.Lines().At(0).Stop
+	// missing headings inserted at the same position, keyed by the position
+	inserts := map[int]string{}
+	for i, h := range headings {
+		if positions[i] >= 0 {
+			continue
+		}
+		pos := len(source)
+		for _, p := range positions[i+1:] {
+			if p >= 0 {
+				pos = p
+				break
+			}
+		}
+		inserts[pos] += BuildHeading(h.Level, h.Text) + "\n\n"
+	}
+	if len(inserts) == 0 {
+		return source
 	}
 
-	// append text after the last position
 	buf := []byte{}
-	buf = append(buf, sourceSelf[:last]...)
-	buf = append(buf, []byte("\n\n"+text)...)
-	sourceSelf = buf
+	last := 0
+	for _, pos := range slices.Sorted(maps.Keys(inserts)) {
+		buf = append(buf, source[last:pos]...)
+		text := inserts[pos]
+		if pos == len(source) {
+			// at the end, separate from the content before with a blank line instead
+			if len(buf) > 0 && !bytes.HasSuffix(buf, []byte("\n")) {
+				buf = append(buf, '\n')
+			}
+			if len(buf) > 0 && !bytes.HasSuffix(buf, []byte("\n\n")) {
+				buf = append(buf, '\n')
+			}
+			text = strings.TrimSuffix(text, "\n")
+		}
+		buf = append(buf, text...)
+		last = pos
+	}
+	buf = append(buf, source[last:]...)
+	return buf
+}
 
-	return sourceSelf
+// lineStart returns the position of the beginning of the line that contains pos
+func lineStart(source []byte, pos int) int {
+	return bytes.LastIndexByte(source[:pos], '\n') + 1
 }
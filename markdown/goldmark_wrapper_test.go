@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark/ast"
 )
 
 var updateGolden = false
@@ -48,27 +49,53 @@ func TestGoldmarkWrapper_Render(t *testing.T) {
 func TestGoldmarkWrapper_FindHeadingAndGetHangingNodes(t *testing.T) {
 	assert := assert.New(t)
 	tests := []struct {
-		name    string
-		wantErr bool
+		name          string
+		inputMarkdown string
+		targetHeading Heading
+		wantLen       int
+		wantErr       error
 	}{
 		{
-			name: "sample",
+			name: "nodes up to the next heading of the same level",
+			inputMarkdown: `# Heading 1
+## Heading 2
+Content under heading 2.
+### Heading 3
+Content under heading 3.
+## Heading 2-2
+Content under heading 2-2.`,
+			targetHeading: NewHeading(2, "Heading 2"),
+			wantLen:       3,
+		},
+		{
+			name: "no matching heading",
+			inputMarkdown: `# Heading 1
+## Heading 2`,
+			targetHeading: NewHeading(3, "Non-existent Heading"),
+			wantErr:       ErrHeadingNotFound,
+		},
+		{
+			name: "more than one matching heading",
+			inputMarkdown: `# Heading 1
+## Heading 2
+Content under the first.
+## Heading 2
+Content under the second.`,
+			targetHeading: NewHeading(2, "Heading 2"),
+			wantErr:       ErrAmbiguousHeading,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filename := "./testdata/sample.md"
-			f, err := os.ReadFile(filename)
-			assert.NoError(err)
-
 			gmw := NewGoldmarkWrapper()
-			h := NewHeading(2, "ordered list")
-			_, hangingNodes := gmw.FindHeadingAndGetHangingNodes(f, h)
-
-			assert.NotEmpty(hangingNodes)
+			heading, hangingNodes, err := gmw.FindHeadingAndGetHangingNodes([]byte(tt.inputMarkdown), tt.targetHeading)
+			assert.ErrorIs(err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(heading)
+			}
+			assert.Len(hangingNodes, tt.wantLen)
 		})
 	}
-
 }
 
 func TestGoldmarkWrapper_InsertTextAtHeadingStart(t *testing.T) {
@@ -79,6 +106,7 @@ func TestGoldmarkWrapper_InsertTextAtHeadingStart(t *testing.T) {
 		targetHeading Heading
 		textToInsert  string
 		expected      string
+		wantErr       error
 	}{
 		{
 			name: "insert text at start of heading with no children",
@@ -117,22 +145,21 @@ Content under heading 3.`,
 Content under heading 2.`,
 			targetHeading: NewHeading(3, "Non-existent Heading"),
 			textToInsert:  "Inserted text.",
-			expected: `# Heading 1
-## Heading 2
-Content under heading 2.`,
+			wantErr:       ErrHeadingNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gmw := NewGoldmarkWrapper()
-			result := gmw.InsertTextAtHeadingStart([]byte(tt.inputMarkdown), tt.targetHeading, tt.textToInsert)
+			result, err := gmw.InsertTextAtHeadingStart([]byte(tt.inputMarkdown), tt.targetHeading, tt.textToInsert)
+			assert.ErrorIs(err, tt.wantErr)
 			assert.Equal(tt.expected, string(result))
 		})
 	}
 }
 
-func TestGoldmarkWrapper_InsertTextAtHeadingEnd(t *testing.T) {
+func TestGoldmarkWrapper_AppendToSection(t *testing.T) {
 	assert := assert.New(t)
 	tests := []struct {
 		name          string
@@ -140,6 +167,7 @@ func TestGoldmarkWrapper_InsertTextAtHeadingEnd(t *testing.T) {
 		targetHeading Heading
 		textToAppend  string
 		expected      string
+		wantErr       error
 	}{
 		{
 			name: "append text after heading with no children",
@@ -178,16 +206,199 @@ Appended text.`,
 Content under heading 2.`,
 			targetHeading: NewHeading(3, "Non-existent Heading"),
 			textToAppend:  "Appended text.",
-			expected: `# Heading 1
-## Heading 2
-Content under heading 2.`,
+			wantErr:       ErrHeadingNotFound,
+		},
+		{
+			name:          "append text before the next section",
+			inputMarkdown: "## Heading 2\n\ncontent\n\n### Heading 3\n\nchild\n\n## Heading 4\n",
+			targetHeading: NewHeading(2, "Heading 2"),
+			textToAppend:  "Appended text.",
+			expected:      "## Heading 2\n\ncontent\n\n### Heading 3\n\nchild\n\nAppended text.\n\n## Heading 4\n",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gmw := NewGoldmarkWrapper()
-			result := gmw.InsertTextAtHeadingEnd([]byte(tt.inputMarkdown), tt.targetHeading, tt.textToAppend)
+			result, err := gmw.AppendToSection([]byte(tt.inputMarkdown), tt.targetHeading, tt.textToAppend)
+			assert.ErrorIs(err, tt.wantErr)
+			assert.Equal(tt.expected, string(result))
+		})
+	}
+}
+
+func TestGoldmarkWrapper_HeadingPathsByLine(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name          string
+		inputMarkdown string
+		expected      [][]string
+	}{
+		{
+			name: "nested headings",
+			inputMarkdown: `# daily memo
+## memos
+### deploy notes
+text
+## todos
+- [ ] todo`,
+			expected: [][]string{
+				{"daily memo"},
+				{"daily memo", "memos"},
+				{"daily memo", "memos", "deploy notes"},
+				{"daily memo", "memos", "deploy notes"},
+				{"daily memo", "todos"},
+				{"daily memo", "todos"},
+			},
+		},
+		{
+			name:          "lines before first heading and heading-like line in code block",
+			inputMarkdown: "preface\n# heading\n```\n# not heading\n```",
+			expected: [][]string{
+				nil,
+				{"heading"},
+				{"heading"},
+				{"heading"},
+				{"heading"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gmw := NewGoldmarkWrapper()
+			result := gmw.HeadingPathsByLine([]byte(tt.inputMarkdown))
+			assert.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestGoldmarkWrapper_HeadingLine(t *testing.T) {
+	source := []byte("# daily memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n```\n## not heading\n```\n")
+	tests := []struct {
+		name     string
+		selector string
+		expected int
+		wantErr  error
+	}{
+		{name: "top level", selector: "daily memo", expected: 1},
+		{name: "second level", selector: "memos", expected: 7},
+		{name: "path", selector: "daily memo > memos", expected: 7},
+		{name: "position", selector: "daily memo/1", expected: 3},
+		{name: "not found", selector: "not heading", wantErr: ErrHeadingNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			gmw := NewGoldmarkWrapper()
+			sel, err := ParseSelector(tt.selector)
+			assert.NoError(err)
+			got, err := gmw.HeadingLine(source, sel)
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.expected, got)
+		})
+	}
+}
+
+func TestGoldmarkWrapper_InsertMissingHeadings(t *testing.T) {
+	assert := assert.New(t)
+	headings := []Heading{
+		NewHeading(1, "daily memo"),
+		NewHeading(2, "todos"),
+		NewHeading(2, "wanttodos"),
+		NewHeading(2, "memos"),
+	}
+	tests := []struct {
+		name          string
+		inputMarkdown string
+		expected      string
+	}{
+		{
+			name:          "up to date",
+			inputMarkdown: "# daily memo\n\n## todos\n\n## wanttodos\n\n## memos\n",
+			expected:      "# daily memo\n\n## todos\n\n## wanttodos\n\n## memos\n",
+		},
+		{
+			name:          "missing in the middle with content",
+			inputMarkdown: "# daily memo\n\n## todos\n\n- [ ] my own todo\n\n## memos\n\nnote\n",
+			expected:      "# daily memo\n\n## todos\n\n- [ ] my own todo\n\n## wanttodos\n\n## memos\n\nnote\n",
+		},
+		{
+			name:          "missing at the end",
+			inputMarkdown: "# daily memo\n\n## todos\n\n## planning (my own section)",
+			expected:      "# daily memo\n\n## todos\n\n## planning (my own section)\n\n## wanttodos\n\n## memos\n",
+		},
+		{
+			name:          "similar heading is not the same",
+			inputMarkdown: "# daily memo\n\n## wanttodos\n\n## memos\n",
+			expected:      "# daily memo\n\n## todos\n\n## wanttodos\n\n## memos\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gmw := NewGoldmarkWrapper()
+			result := gmw.InsertMissingHeadings([]byte(tt.inputMarkdown), headings)
+			assert.Equal(tt.expected, string(result))
+		})
+	}
+}
+
+func TestGoldmarkWrapper_ReplaceHangingNodes(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name          string
+		inputMarkdown string
+		targetHeading Heading
+		replacement   string
+		expected      string
+		wantErr       error
+	}{
+		{
+			name:          "replace nodes of heading followed by another heading",
+			inputMarkdown: "# Heading 1\n\n## Heading 2\n\n- old\n\n### Heading 3\n\nchild\n\n## Heading 4\n",
+			targetHeading: NewHeading(2, "Heading 2"),
+			replacement:   "\n\n- new",
+			expected:      "# Heading 1\n\n## Heading 2\n\n- new\n\n## Heading 4\n",
+		},
+		{
+			name:          "replace nodes of last heading",
+			inputMarkdown: "# Heading 1\n\n## Heading 2\n\n- old\n",
+			targetHeading: NewHeading(2, "Heading 2"),
+			replacement:   "\n\n- new",
+			expected:      "# Heading 1\n\n## Heading 2\n\n- new\n",
+		},
+		{
+			name:          "replace with no nodes",
+			inputMarkdown: "## Heading 2\n\n- old\n\n## Heading 3\n",
+			targetHeading: NewHeading(2, "Heading 2"),
+			replacement:   "",
+			expected:      "## Heading 2\n\n## Heading 3\n",
+		},
+		{
+			name:          "no matching heading",
+			inputMarkdown: "## Heading 2\n\n- old\n",
+			targetHeading: NewHeading(2, "Non-existent Heading"),
+			replacement:   "\n\n- new",
+			wantErr:       ErrHeadingNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gmw := NewGoldmarkWrapper()
+			replacement := []byte(tt.replacement)
+			nodes := []ast.Node{}
+			for c := gmw.Parse(replacement).FirstChild(); c != nil; c = c.NextSibling() {
+				nodes = append(nodes, c)
+			}
+			result, err := gmw.ReplaceHangingNodes([]byte(tt.inputMarkdown), tt.targetHeading, replacement, nodes)
+			assert.ErrorIs(err, tt.wantErr)
 			assert.Equal(tt.expected, string(result))
 		})
 	}
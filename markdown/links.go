@@ -0,0 +1,47 @@
+package markdown
+
+import (
+	"slices"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Span is a range of the source
+type Span struct {
+	Start int
+	Stop  int
+}
+
+// LinkDestinations returns where the destinations of the links are written in the source, in order and each once.
+// A destination in angle brackets spans inside them, and links sharing a link reference definition share its destination.
+// Destinations that cannot be located in the source, such as of autolinks, are left out.
+func (gmw *GoldmarkWrapper) LinkDestinations(source []byte) []Span {
+	var spans []Span
+	_ = ast.Walk(gmw.Parse(source), func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if start, ok := offsetIn(source, link.Destination); ok {
+			span := Span{Start: start, Stop: start + len(link.Destination)}
+			if !slices.Contains(spans, span) {
+				spans = append(spans, span)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	slices.SortFunc(spans, func(a, b Span) int { return a.Start - b.Start })
+	return spans
+}
+
+// offsetIn returns the position of the bytes in the source if the parser took them out of it without copying
+func offsetIn(source, b []byte) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	offset := cap(source) - cap(b)
+	if offset < 0 || offset+len(b) > len(source) || &source[offset] != &b[0] {
+		return 0, false
+	}
+	return offset, true
+}
@@ -0,0 +1,46 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldmarkWrapper_LinkDestinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "inline links",
+			source: "- [a](a.md#todos)\n- [b](<b c.md>) and [d](d.md \"title\")\n",
+			want:   []string{"a.md#todos", "b c.md", "d.md"},
+		},
+		{
+			name:   "parentheses in the destination",
+			source: "[memo](2025年01月01日(水).md)\n",
+			want:   []string{"2025年01月01日(水).md"},
+		},
+		{
+			name:   "reference links",
+			source: "[a][1] and [b][1]\n\n[1]: ref.md\n",
+			want:   []string{"ref.md"},
+		},
+		{
+			name:   "not links",
+			source: "`[a](code.md)` and <https://example.com> and ![image](img.png)\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte(tt.source)
+			var got []string
+			for _, s := range NewGoldmarkWrapper().LinkDestinations(source) {
+				got = append(got, string(source[s.Start:s.Stop]))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,8 +3,13 @@ package markdown
 import (
 	"fmt"
 	"strings"
+
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
 )
 
+// Text2tag replaces spaces with hyphens and removes some punctuations.
+//
+// Deprecated: use Slugger, which generates the anchors that markdown viewers do.
 func Text2tag(text string) string {
 	var tag = text
 	tag = strings.ReplaceAll(tag, " ", "-")
@@ -44,3 +49,24 @@ func BuildCheckbox(text string, checked bool) string {
 		return "- [ ] " + text
 	}
 }
+
+// BuildList builds a bullet list item with the bullet marker of the renderer options
+func (gmw *GoldmarkWrapper) BuildList(text string) string {
+	return string(myrenderer.BulletMarkerOf(gmw.rendererOptions...)) + " " + text
+}
+
+// BuildOrderedList builds an ordered list item numbered as the renderer options number the items of a list starting at 1
+func (gmw *GoldmarkWrapper) BuildOrderedList(order int, text string) string {
+	if myrenderer.OrderedListStyleOf(gmw.rendererOptions...) == myrenderer.ORDEREDLISTSTYLE_ONE {
+		order = 1
+	}
+	return BuildOrderedList(order, text)
+}
+
+// BuildCheckbox builds a task list item with the bullet marker of the renderer options
+func (gmw *GoldmarkWrapper) BuildCheckbox(text string, checked bool) string {
+	if checked {
+		return gmw.BuildList("[x] " + text)
+	}
+	return gmw.BuildList("[ ] " + text)
+}
@@ -2,6 +2,9 @@ package markdown
 
 import (
 	"testing"
+
+	myrenderer "github.com/hirotoni/memo/markdown/renderer"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestText2tag(t *testing.T) {
@@ -226,3 +229,39 @@ func TestBuildCheckbox(t *testing.T) {
 		})
 	}
 }
+
+func TestGoldmarkWrapper_builders(t *testing.T) {
+	tests := []struct {
+		name        string
+		gmw         *GoldmarkWrapper
+		wantList    string
+		wantOrdered string
+		wantTask    string
+	}{
+		{
+			name:        "default",
+			gmw:         NewGoldmarkWrapper(),
+			wantList:    "- test",
+			wantOrdered: "3. test",
+			wantTask:    "- [x] test",
+		},
+		{
+			name: "renderer options",
+			gmw: NewGoldmarkWrapper(WithRendererOptions(
+				myrenderer.WithBulletMarker('*'),
+				myrenderer.WithOrderedListStyle(myrenderer.ORDEREDLISTSTYLE_ONE),
+			)),
+			wantList:    "* test",
+			wantOrdered: "1. test",
+			wantTask:    "* [x] test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.wantList, tt.gmw.BuildList("test"))
+			assert.Equal(tt.wantOrdered, tt.gmw.BuildOrderedList(3, "test"))
+			assert.Equal(tt.wantTask, tt.gmw.BuildCheckbox("test", true))
+		})
+	}
+}
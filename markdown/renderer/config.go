@@ -2,30 +2,133 @@ package renderer
 
 import "github.com/yuin/goldmark/renderer"
 
-/* this file does nothing, but keep it for potential use in the future. */
+// OrderedListStyle is how the items of ordered lists are numbered
+type OrderedListStyle string
 
 const (
-	optSampleOption  renderer.OptionName = "SampleOption"
-	optSampleOption2 renderer.OptionName = "SampleOption2"
+	ORDEREDLISTSTYLE_SEQUENTIAL OrderedListStyle = "sequential" // 1. 2. 3.
+	ORDEREDLISTSTYLE_ONE        OrderedListStyle = "one"        // 1. 1. 1. every item has the start number of the list
+)
+
+// LineEnding is the line ending of rendered markdown
+type LineEnding string
+
+const (
+	LINEENDING_LF   LineEnding = "lf"
+	LINEENDING_CRLF LineEnding = "crlf"
+)
+
+const (
+	optBulletMarker           renderer.OptionName = "BulletMarker"
+	optOrderedListStyle       renderer.OptionName = "OrderedListStyle"
+	optEmphasisMarker         renderer.OptionName = "EmphasisMarker"
+	optListIndent             renderer.OptionName = "ListIndent"
+	optBlankLineBetweenBlocks renderer.OptionName = "BlankLineBetweenBlocks"
+	optLineEnding             renderer.OptionName = "LineEnding"
 )
 
 type MarkdownRendererConfig struct {
-	sampleOption  bool
-	sampleOption2 bool
+	bulletMarker           byte             // -, * or +. 0 keeps the marker as written
+	orderedListStyle       OrderedListStyle // how ordered list items are numbered
+	emphasisMarker         byte             // * or _
+	listIndent             int              // columns from a list marker to the content. 0 keeps the indent as written
+	blankLineBetweenBlocks bool             // separate blocks by a blank line even where the source does not
+	lineEnding             LineEnding       // line ending of the output
 }
 
 func NewMarkdownRendererConfig() *MarkdownRendererConfig {
 	return &MarkdownRendererConfig{
-		sampleOption:  true,
-		sampleOption2: true,
+		orderedListStyle: ORDEREDLISTSTYLE_SEQUENTIAL,
+		emphasisMarker:   '*',
+		lineEnding:       LINEENDING_LF,
 	}
 }
 
 func (c *MarkdownRendererConfig) SetOption(name renderer.OptionName, value interface{}) {
 	switch name {
-	case optSampleOption:
-		c.sampleOption = value.(bool)
-	case optSampleOption2:
-		c.sampleOption2 = value.(bool)
+	case optBulletMarker:
+		c.bulletMarker = value.(byte)
+	case optOrderedListStyle:
+		c.orderedListStyle = value.(OrderedListStyle)
+	case optEmphasisMarker:
+		c.emphasisMarker = value.(byte)
+	case optListIndent:
+		c.listIndent = value.(int)
+	case optBlankLineBetweenBlocks:
+		c.blankLineBetweenBlocks = value.(bool)
+	case optLineEnding:
+		c.lineEnding = value.(LineEnding)
+	}
+}
+
+// option sets a value of MarkdownRendererConfig through goldmark.WithRendererOptions
+type option struct {
+	name  renderer.OptionName
+	value interface{}
+}
+
+func (o *option) SetConfig(c *renderer.Config) {
+	c.Options[o.name] = o.value
+}
+
+// WithBulletMarker writes bullet lists with the marker: '-', '*' or '+'.
+// A list right after another bullet list keeps its marker, or the two lists would be merged.
+func WithBulletMarker(marker byte) renderer.Option {
+	return &option{name: optBulletMarker, value: marker}
+}
+
+func WithOrderedListStyle(style OrderedListStyle) renderer.Option {
+	return &option{name: optOrderedListStyle, value: style}
+}
+
+// WithEmphasisMarker writes emphasis with the marker: '*' or '_'.
+// Emphasis inside a word is written with '*' anyway, as '_' does not work there.
+func WithEmphasisMarker(marker byte) renderer.Option {
+	return &option{name: optEmphasisMarker, value: marker}
+}
+
+// WithListIndent indents the content of list items, and nested lists, by the width from the marker.
+// The width is widened for markers that do not fit, such as "10."
+func WithListIndent(width int) renderer.Option {
+	return &option{name: optListIndent, value: width}
+}
+
+// WithBlankLineBetweenBlocks separates sibling blocks by a blank line, except in tight lists which it would make loose
+func WithBlankLineBetweenBlocks(blank bool) renderer.Option {
+	return &option{name: optBlankLineBetweenBlocks, value: blank}
+}
+
+func WithLineEnding(ending LineEnding) renderer.Option {
+	return &option{name: optLineEnding, value: ending}
+}
+
+// LineEndingOf returns the line ending the options set, LINEENDING_LF if none
+func LineEndingOf(opts ...renderer.Option) LineEnding {
+	return configOf(opts...).lineEnding
+}
+
+// BulletMarkerOf returns the bullet marker the options set, '-' if none
+func BulletMarkerOf(opts ...renderer.Option) byte {
+	if marker := configOf(opts...).bulletMarker; marker != 0 {
+		return marker
+	}
+	return '-'
+}
+
+// OrderedListStyleOf returns how the options number ordered list items, ORDEREDLISTSTYLE_SEQUENTIAL if not set
+func OrderedListStyleOf(opts ...renderer.Option) OrderedListStyle {
+	return configOf(opts...).orderedListStyle
+}
+
+// configOf returns the config that the options set on top of the defaults
+func configOf(opts ...renderer.Option) *MarkdownRendererConfig {
+	rc := renderer.NewConfig()
+	for _, opt := range opts {
+		opt.SetConfig(rc)
+	}
+	c := NewMarkdownRendererConfig()
+	for name, value := range rc.Options {
+		c.SetOption(name, value)
 	}
+	return c
 }
@@ -1,8 +1,13 @@
 package renderer
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/ast"
 	extast "github.com/yuin/goldmark/extension/ast"
@@ -12,6 +17,7 @@ import (
 
 type MarkdownRenderer struct {
 	MarkdownRendererConfig
+	funcs funcsCollector // render funcs by kind, used to render inlines into strings
 }
 
 func NewMarkdownRenderer() *MarkdownRenderer {
@@ -24,10 +30,10 @@ func (r *MarkdownRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer
 	// blocks
 	reg.Register(ast.KindDocument, r.renderDocument)
 	reg.Register(ast.KindHeading, r.renderHeading)
-	// reg.Register(ast.KindBlockquote, r.renderBlockquote)
-	// reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
-	// reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
-	// reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+	reg.Register(ast.KindBlockquote, r.renderBlockquote)
+	reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
 	reg.Register(ast.KindList, r.renderList)
 	reg.Register(ast.KindListItem, r.renderListItem)
 	reg.Register(ast.KindParagraph, r.renderParagraph)
@@ -38,12 +44,38 @@ func (r *MarkdownRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer
 	reg.Register(ast.KindAutoLink, r.renderAutoLink)
 	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
 	reg.Register(ast.KindEmphasis, r.renderEmphasis)
-	// reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindImage, r.renderImage)
 	reg.Register(ast.KindLink, r.renderLink)
-	// reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
 	reg.Register(ast.KindText, r.renderText)
-	// reg.Register(ast.KindString, r.renderString)
+	reg.Register(ast.KindString, r.renderString)
 	reg.Register(extast.KindTaskCheckBox, r.renderTaskCheckBox)
+
+	// GFM
+	reg.Register(extast.KindTable, r.renderTable)
+	reg.Register(extast.KindStrikethrough, r.renderStrikethrough)
+}
+
+// funcsCollector collects render funcs registered by RegisterFuncs
+type funcsCollector map[ast.NodeKind]renderer.NodeRendererFunc
+
+func (c funcsCollector) Register(kind ast.NodeKind, f renderer.NodeRendererFunc) {
+	c[kind] = f
+}
+
+// CanRender reports whether the renderer writes nodes of the kind as markdown.
+// Parts of tables are written along with the tables.
+func (r *MarkdownRenderer) CanRender(kind ast.NodeKind) bool {
+	switch kind {
+	case extast.KindTableHeader, extast.KindTableRow, extast.KindTableCell:
+		return true
+	}
+	if r.funcs == nil {
+		r.funcs = funcsCollector{}
+		r.RegisterFuncs(r.funcs)
+	}
+	_, ok := r.funcs[kind]
+	return ok
 }
 
 // MARK: blocks
@@ -58,13 +90,16 @@ func (r *MarkdownRenderer) renderHeading(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Heading)
 	if entering {
-		if n.PreviousSibling() != nil && n.HasBlankPreviousLines() {
-			_, _ = w.WriteString("\n\n")
+		if n.PreviousSibling() != nil {
+			r.writeSeparator(w, source, n)
 		}
-		_, _ = w.WriteString(strings.Repeat("#", n.Level) + " ")
-	} else {
-		if n.NextSibling() == nil {
-			_, _ = w.WriteString("\n")
+		r.write(w, strings.Repeat("#", n.Level)+" ")
+	} else if n.NextSibling() == nil {
+		switch n.Parent().(type) {
+		case *ast.ListItem, *ast.Blockquote:
+			// the block after the container breaks the line
+		default:
+			r.write(w, "\n")
 		}
 	}
 	return ast.WalkContinue, nil
@@ -74,36 +109,132 @@ func (r *MarkdownRenderer) renderParagraph(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Paragraph)
 	if entering {
-		if n.HasBlankPreviousLines() {
-			_, _ = w.WriteString("\n\n")
+		r.writeSeparator(w, source, n)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *MarkdownRenderer) renderBlockquote(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Blockquote)
+	if prev, ok := n.PreviousSibling().(*ast.HTMLBlock); ok && prev.HasClosure() && n.ChildCount() == 0 {
+		// the parser leaves an empty blockquote after an html block closed in a blockquote
+		return ast.WalkSkipChildren, nil
+	}
+	if entering {
+		r.writeSeparator(w, source, n)
+		r.write(w, "> ")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *MarkdownRenderer) renderCodeBlock(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.CodeBlock)
+	if entering {
+		r.writeSeparator(w, source, n)
+		prefix := r.linePrefix(n)
+		for i := 0; i < n.Lines().Len(); i++ {
+			if i > 0 {
+				r.write(w, "\n")
+			}
+			segment := n.Lines().At(i)
+			line := strings.TrimRight(string(segment.Value(source)), "\r\n")
+			if strings.TrimSpace(line) == "" {
+				// keep blank lines in code free of trailing spaces
+				if i > 0 {
+					r.write(w, strings.TrimRight(prefix, " "))
+				}
+				continue
+			}
+			if i > 0 {
+				r.write(w, prefix)
+			}
+			r.write(w, "    "+line)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *MarkdownRenderer) renderFencedCodeBlock(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.FencedCodeBlock)
+	if entering {
+		r.writeSeparator(w, source, n)
+		prefix := r.linePrefix(n)
+		fence := fenceOf(n, source)
+
+		r.write(w, fence)
+		if n.Info != nil {
+			r.write(w, string(n.Info.Segment.Value(source)))
+		}
+		for i := 0; i < n.Lines().Len(); i++ {
+			segment := n.Lines().At(i)
+			line := strings.TrimRight(string(segment.Value(source)), "\r\n")
+			r.write(w, "\n")
+			if line == "" {
+				r.write(w, strings.TrimRight(prefix, " "))
+			} else {
+				r.write(w, prefix+line)
+			}
 		}
+		r.write(w, "\n"+prefix+fence)
 	}
 	return ast.WalkContinue, nil
 }
 
+func (r *MarkdownRenderer) renderHTMLBlock(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.HTMLBlock)
+	if entering {
+		r.writeSeparator(w, source, n)
+		prefix := r.linePrefix(n)
+
+		var lines []string
+		for i := 0; i < n.Lines().Len(); i++ {
+			segment := n.Lines().At(i)
+			lines = append(lines, strings.TrimRight(string(segment.Value(source)), "\r\n"))
+		}
+		if n.HasClosure() {
+			lines = append(lines, strings.TrimRight(string(n.ClosureLine.Value(source)), "\r\n"))
+		}
+		for i, line := range lines {
+			switch {
+			case i == 0:
+				r.write(w, line)
+			case line == "":
+				r.write(w, "\n"+strings.TrimRight(prefix, " "))
+			default:
+				r.write(w, "\n"+prefix+line)
+			}
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderText writes the text as written in the source.
+// Segments keep backslash escapes and entities, so markdown-significant characters stay escaped.
 func (r *MarkdownRenderer) renderText(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Text)
 	if entering {
-		p := n.Parent()
-		if p == nil {
+		if n.Parent() == nil {
 			return ast.WalkContinue, nil
 		}
-		if p.Kind() == ast.KindLink {
-			// r.renderLink() renders text in advance. no rendering needed here.
-			return ast.WalkContinue, nil
-		}
-
-		w.WriteString(string(n.Text(source)))
 
-		if n.SoftLineBreak() {
-			w.WriteString("\n")
+		r.write(w, string(n.Text(source)))
 
-			pp := p.Parent()
-			if pp, ok := pp.(*ast.ListItem); ok {
-				// ListItem - TextBlock - Text(SoftLineBreak)
-				w.WriteString(strings.Repeat(" ", pp.Offset))
+		if n.HardLineBreak() {
+			// keep the form in the source: a backslash or trailing spaces
+			if n.Segment.Stop < len(source) && source[n.Segment.Stop] == '\\' {
+				r.write(w, "\\")
+			} else {
+				r.write(w, "  ")
 			}
+			r.write(w, "\n"+r.linePrefix(n))
+		} else if n.SoftLineBreak() {
+			// e.g. ListItem - TextBlock - Text(SoftLineBreak)
+			r.write(w, "\n"+r.linePrefix(n))
 		}
 	}
 	return ast.WalkContinue, nil
@@ -113,8 +244,13 @@ func (r *MarkdownRenderer) renderList(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.List)
 	if entering {
-		if n.HasBlankPreviousLines() {
-			_, _ = w.WriteString("\n\n")
+		if _, nested := n.Parent().(*ast.ListItem); nested {
+			// list items of nested lists break lines by themselves
+			if n.HasBlankPreviousLines() {
+				r.write(w, "\n"+strings.TrimRight(r.linePrefix(n), " "))
+			}
+		} else {
+			r.writeSeparator(w, source, n)
 		}
 	}
 	return ast.WalkContinue, nil
@@ -137,31 +273,22 @@ func (r *MarkdownRenderer) renderListItem(
 
 	if entering {
 		// If it is not the first element of the list or it is a nested listitems, add a line break
+		// and increase the indent for nested lists
 		if n.PreviousSibling() != nil || pp.Kind() == ast.KindListItem {
-			w.WriteString("\n")
-		}
-
-		// Increase the indent for nested lists
-		curpp := pp
-		for curpp != nil {
-			li, ok := curpp.(*ast.ListItem)
-			if ok {
-				w.WriteString(strings.Repeat(" ", li.Offset))
+			if l, ok := p.(*ast.List); ok && !l.IsTight && n.PreviousSibling() != nil {
+				// items of loose lists are separated by blank lines
+				r.write(w, "\n"+strings.TrimRight(r.linePrefix(n), " "))
 			}
-			curpp = safeGroundParent(curpp)
+			r.write(w, "\n"+r.linePrefix(n))
 		}
 
 		if p, ok := p.(*ast.List); ok {
-			if p.IsOrdered() {
-				order := p.Start
-				for node.PreviousSibling() != nil {
-					order++
-					node = node.PreviousSibling()
-				}
-				w.WriteString(fmt.Sprintf("%d", order))
+			marker := r.listItemMarker(p, n)
+			if r.listIndent > 0 {
+				r.write(w, marker+strings.Repeat(" ", r.itemOffset(n)-len(marker)))
+			} else {
+				r.write(w, marker+" ")
 			}
-
-			w.WriteString(string(p.Marker) + " ")
 		}
 	}
 	return ast.WalkContinue, nil
@@ -169,29 +296,98 @@ func (r *MarkdownRenderer) renderListItem(
 
 func (r *MarkdownRenderer) renderTextBlock(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	// n := node.(*ast.TextBlock)
-	// nothing to do
+	n := node.(*ast.TextBlock)
+	if entering {
+		// e.g. a heading in the list item before the text
+		r.writeSeparator(w, source, n)
+	}
 	return ast.WalkContinue, nil
 }
 
 func (r *MarkdownRenderer) renderThematicBreak(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		w.WriteString("\n\n---")
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	if node.PreviousSibling() == nil {
+		r.writeSeparator(w, source, node)
+	} else {
+		// a blank line keeps the break from underlining the paragraph before as a setext heading
+		prefix := r.linePrefix(node)
+		r.write(w, "\n"+strings.TrimRight(prefix, " ")+"\n"+prefix)
+	}
+	if _, ok := node.Parent().(*ast.ListItem); ok && node.PreviousSibling() == nil {
+		// "- ---" would be a break by itself
+		r.write(w, "***")
+		return ast.WalkContinue, nil
 	}
+	r.write(w, "---")
 	return ast.WalkContinue, nil
 }
 
+func (r *MarkdownRenderer) renderTable(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*extast.Table)
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	// render every cell in advance to align columns
+	var rows [][]string
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, r.renderInline(source, cell))
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(n.Alignments))
+	for i := range widths {
+		widths[i] = 3 // the shortest delimiter
+		for _, cells := range rows {
+			if i < len(cells) {
+				widths[i] = max(widths[i], displayWidth(cells[i]))
+			}
+		}
+	}
+
+	r.writeSeparator(w, source, n)
+	prefix := r.linePrefix(n)
+	for i, cells := range rows {
+		if i > 0 {
+			r.write(w, "\n"+prefix)
+		}
+		r.write(w, "|")
+		for j, width := range widths {
+			var cell string
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			r.write(w, " "+padCell(cell, width, n.Alignments[j])+" |")
+		}
+
+		if i == 0 {
+			// delimiter row right after the header
+			r.write(w, "\n"+prefix+"|")
+			for j, width := range widths {
+				r.write(w, " "+delimiterCell(width, n.Alignments[j])+" |")
+			}
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
 // MARK: inlines
 
 func (r *MarkdownRenderer) renderEmphasis(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Emphasis)
-	if entering {
-		w.WriteString(strings.Repeat("*", n.Level))
-	} else {
-		w.WriteString(strings.Repeat("*", n.Level))
+	marker := string(r.emphasisMarker)
+	if marker == "_" && isIntraword(n, source) {
+		marker = "*"
 	}
+	r.write(w, strings.Repeat(marker, n.Level))
 	return ast.WalkContinue, nil
 }
 
@@ -200,21 +396,39 @@ func (r *MarkdownRenderer) renderTaskCheckBox(
 	n := node.(*extast.TaskCheckBox)
 	if entering {
 		if n.IsChecked {
-			_, _ = w.WriteString("[x] ")
+			r.write(w, "[x] ")
 		} else {
-			_, _ = w.WriteString("[ ] ")
+			r.write(w, "[ ] ")
 		}
 	}
 	return ast.WalkContinue, nil
 }
 
+func (r *MarkdownRenderer) renderStrikethrough(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*extast.Strikethrough)
+	r.write(w, tildesOf(n, source))
+	return ast.WalkContinue, nil
+}
+
 func (r *MarkdownRenderer) renderLink(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Link)
 	if entering {
-		// NOTE As of goldmark v1.7.1, ast.Link.Title is not set by default markdown parser, so that n.Text(source) is used here instead.
-		// n.Text(source) retrieves text from n's child node (ast.Text) in advance to node-walking operation.
-		w.WriteString(fmt.Sprintf("[%s](%s)", n.Text(source), n.Destination))
+		r.write(w, "[")
+	} else {
+		r.write(w, "]("+linkDestination(n.Destination)+linkTitle(n.Title)+")")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *MarkdownRenderer) renderImage(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Image)
+	if entering {
+		r.write(w, "![")
+	} else {
+		r.write(w, "]("+linkDestination(n.Destination)+linkTitle(n.Title)+")")
 	}
 	return ast.WalkContinue, nil
 }
@@ -223,17 +437,469 @@ func (r *MarkdownRenderer) renderAutoLink(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.AutoLink)
 	if entering {
-		w.WriteString(fmt.Sprint(string(n.URL(source))))
+		// the label is the link as written, while the url may have a protocol added. e.g. www.example.com
+		label := string(n.Label(source))
+		if isBracketedAutoLink(n, source) {
+			r.write(w, "<"+label+">")
+		} else {
+			r.write(w, label)
+		}
 	}
 	return ast.WalkContinue, nil
 }
 
-func (r *MarkdownRenderer) renderCodeSpan(
+func (r *MarkdownRenderer) renderRawHTML(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.RawHTML)
 	if entering {
-		w.WriteString("`")
-	} else {
-		w.WriteString("`")
+		// html spanning lines has a segment per line
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			value := string(segment.Value(source))
+			r.write(w, value)
+			if strings.HasSuffix(value, "\n") && i < n.Segments.Len()-1 {
+				r.write(w, r.linePrefix(n))
+			}
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *MarkdownRenderer) renderString(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.String)
+	if entering {
+		if n.IsRaw() || n.IsCode() {
+			r.write(w, string(n.Value))
+		} else {
+			// unlike texts, strings are not written in the source, so escape them here
+			r.write(w, escapeMarkdown(string(n.Value)))
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderCodeSpan writes the code between backtick strings longer than any in the code.
+// Spaces pad the code where the parser would take its first or last character for a part of the fence or strip it.
+func (r *MarkdownRenderer) renderCodeSpan(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	code := codeSpanContent(node, source)
+	fence := strings.Repeat("`", longestRun(code, '`')+1)
+	pad := strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") ||
+		len(strings.TrimSpace(code)) > 0 && isSpaceOrNewline(code[0]) && isSpaceOrNewline(code[len(code)-1])
+	if pad {
+		if entering {
+			fence += " "
+		} else {
+			fence = " " + fence
+		}
 	}
+	r.write(w, fence)
 	return ast.WalkContinue, nil
 }
+
+// MARK: helpers
+
+// codeSpanContent returns the code of the code span as parsed
+func codeSpanContent(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			sb.Write(c.Segment.Value(source))
+		case *ast.String:
+			sb.Write(c.Value)
+		}
+	}
+	return sb.String()
+}
+
+// longestRun returns the length of the longest run of the byte in the string
+func longestRun(s string, b byte) int {
+	var longest, run int
+	for i := 0; i < len(s); i++ {
+		if s[i] != b {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+func isSpaceOrNewline(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r'
+}
+
+// linePrefix returns the prefix of lines inside the node, following the containers of the node.
+// e.g. "  > " for a paragraph in a blockquote in a list item
+func (r *MarkdownRenderer) linePrefix(n ast.Node) string {
+	var prefixes []string
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		switch p := p.(type) {
+		case *ast.ListItem:
+			prefixes = append(prefixes, strings.Repeat(" ", r.itemOffset(p)))
+		case *ast.Blockquote:
+			prefixes = append(prefixes, "> ")
+		}
+	}
+	slices.Reverse(prefixes)
+	return strings.Join(prefixes, "")
+}
+
+// write writes the string with the line ending of the config
+func (r *MarkdownRenderer) write(w util.BufWriter, s string) {
+	if r.lineEnding == LINEENDING_CRLF {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+	}
+	_, _ = w.WriteString(s)
+}
+
+// listItemMarker returns the marker of the list item. e.g. "-", "2."
+func (r *MarkdownRenderer) listItemMarker(l *ast.List, item ast.Node) string {
+	if !l.IsOrdered() {
+		if prev, ok := l.PreviousSibling().(*ast.List); r.bulletMarker != 0 && !(ok && !prev.IsOrdered()) {
+			return string(r.bulletMarker)
+		}
+		return string(l.Marker)
+	}
+	order := l.Start
+	if r.orderedListStyle != ORDEREDLISTSTYLE_ONE {
+		for c := item.PreviousSibling(); c != nil; c = c.PreviousSibling() {
+			order++
+		}
+	}
+	return fmt.Sprintf("%d%c", order, l.Marker)
+}
+
+// itemOffset returns the width from the marker of the list item to its content, which its following lines are indented by
+func (r *MarkdownRenderer) itemOffset(li *ast.ListItem) int {
+	l, ok := li.Parent().(*ast.List)
+	if !ok {
+		return li.Offset
+	}
+	width := len(r.listItemMarker(l, li)) + 1
+	if r.listIndent > 0 {
+		return max(r.listIndent, width)
+	}
+	return max(li.Offset, width)
+}
+
+// inTightList reports whether the block is in an item of a tight list
+func inTightList(n ast.Node) bool {
+	if li, ok := n.Parent().(*ast.ListItem); ok {
+		l, ok := li.Parent().(*ast.List)
+		return ok && l.IsTight
+	}
+	return false
+}
+
+// isIntraword reports whether the emphasis touches letters or digits around it, e.g. foo*bar*
+func isIntraword(n ast.Node, source []byte) bool {
+	if prev, ok := n.PreviousSibling().(*ast.Text); ok && !prev.SoftLineBreak() && !prev.HardLineBreak() {
+		c, _ := utf8.DecodeLastRune(prev.Segment.Value(source))
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return true
+		}
+	}
+	if next, ok := n.NextSibling().(*ast.Text); ok {
+		c, _ := utf8.DecodeRune(next.Segment.Value(source))
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSeparator breaks the line before the block, leaving a blank line if the source has one.
+// A blank line always follows a blockquote, or the block would continue the quote lazily.
+// With blankLineBetweenBlocks, a blank line separates the block from the previous one outside tight lists.
+// The first block in a list item or a blockquote follows the marker without a break.
+func (r *MarkdownRenderer) writeSeparator(w util.BufWriter, source []byte, n ast.Node) {
+	prev := n.PreviousSibling()
+	if prev == nil {
+		switch n.Parent().(type) {
+		case *ast.ListItem, *ast.Blockquote:
+			return
+		}
+	}
+
+	prefix := r.linePrefix(n)
+	switch {
+	case hasBlankPreviousLine(source, n) || (prev != nil && prev.Kind() == ast.KindBlockquote) ||
+		(r.blankLineBetweenBlocks && prev != nil && !inTightList(n)):
+		r.write(w, "\n"+strings.TrimRight(prefix, " ")+"\n"+prefix)
+	case prev != nil:
+		r.write(w, "\n"+prefix)
+	}
+}
+
+// hasBlankPreviousLine reports whether a blank line precedes the block.
+// Blocks in blockquotes are not marked by the parser, so the line before the block is looked up in the source.
+func hasBlankPreviousLine(source []byte, n ast.Node) bool {
+	if n.HasBlankPreviousLines() {
+		return true
+	}
+	pos, ok := blockStart(n)
+	if !ok {
+		return false
+	}
+	lineStart := bytes.LastIndexByte(source[:pos], '\n')
+	if lineStart < 0 {
+		return false
+	}
+	prevLine := source[bytes.LastIndexByte(source[:lineStart], '\n')+1 : lineStart]
+	return len(bytes.Trim(prevLine, "> \t\r")) == 0
+}
+
+// blockStart returns a position in the first line of the block
+func blockStart(n ast.Node) (int, bool) {
+	if fcb, ok := n.(*ast.FencedCodeBlock); ok {
+		// the opening fence is not a part of the lines
+		switch {
+		case fcb.Info != nil:
+			return fcb.Info.Segment.Start, true
+		case fcb.Lines().Len() > 0:
+			return max(fcb.Lines().At(0).Start-1, 0), true
+		default:
+			return 0, false
+		}
+	}
+	for c := n; c != nil; c = c.FirstChild() {
+		if c.Type() == ast.TypeBlock && c.Lines().Len() > 0 {
+			return c.Lines().At(0).Start, true
+		}
+	}
+	return 0, false
+}
+
+// fenceOf returns the opening fence of the fenced code block as written in the source, or "```" if not found
+func fenceOf(n *ast.FencedCodeBlock, source []byte) string {
+	// the fence is on the line before the info or the first line of the code
+	var line []byte
+	switch {
+	case n.Info != nil:
+		start := n.Info.Segment.Start
+		line = source[bytes.LastIndexByte(source[:start], '\n')+1 : start]
+	case n.Lines().Len() > 0:
+		end := n.Lines().At(0).Start
+		end = bytes.LastIndexByte(source[:end], '\n')
+		if end < 0 {
+			return "```"
+		}
+		line = source[bytes.LastIndexByte(source[:end], '\n')+1 : end]
+	default:
+		return "```"
+	}
+
+	line = bytes.TrimRight(line, " \t\r")
+	i := len(line)
+	for i > 0 && line[i-1] == line[len(line)-1] && (line[i-1] == '`' || line[i-1] == '~') {
+		i--
+	}
+	if len(line)-i < 3 {
+		return "```"
+	}
+	return string(line[i:])
+}
+
+// linkDestination returns the destination of a link or an image, in angle brackets if it cannot be written bare
+func linkDestination(dest []byte) string {
+	depth := 0
+	balanced := true
+	for i := 0; i < len(dest); i++ {
+		switch dest[i] {
+		case '\\':
+			i++ // escaped
+		case '(':
+			depth++
+		case ')':
+			depth--
+			balanced = balanced && depth >= 0
+		}
+	}
+	if bytes.ContainsAny(dest, " \t<") || !balanced || depth != 0 {
+		return "<" + string(dest) + ">"
+	}
+	return string(dest)
+}
+
+// linkTitle returns the title of a link or an image with a leading space, quoted with the delimiter not used in the title.
+// The title keeps backslash escapes as written in the source.
+func linkTitle(title []byte) string {
+	if len(title) == 0 {
+		return ""
+	}
+	switch {
+	case !containsUnescaped(title, '"'):
+		return ` "` + string(title) + `"`
+	case !containsUnescaped(title, '\''):
+		return " '" + string(title) + "'"
+	case !containsUnescaped(title, '(') && !containsUnescaped(title, ')'):
+		return " (" + string(title) + ")"
+	default:
+		return ` "` + escapeUnescaped(title, '"') + `"`
+	}
+}
+
+// containsUnescaped reports whether the character appears in s without a preceding backslash
+func containsUnescaped(s []byte, c byte) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return true
+		}
+	}
+	return false
+}
+
+// escapeUnescaped escapes the character in s where it is not escaped yet
+func escapeUnescaped(s []byte, c byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			sb.WriteByte(s[i])
+			if i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			}
+		case c:
+			sb.WriteString("\\" + string(c))
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// escapeMarkdown escapes characters which would start inline markup
+func escapeMarkdown(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>&~|!", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// isBracketedAutoLink reports whether the autolink is written in angle brackets in the source, e.g. <https://example.com>.
+// The parser does not record it, so the source around the neighbouring texts is looked up.
+// Without neighbours, links other than www ones are considered bracketed, which keeps them links anyway.
+func isBracketedAutoLink(n *ast.AutoLink, source []byte) bool {
+	label := n.Label(source)
+	if next, ok := n.NextSibling().(*ast.Text); ok {
+		end := next.Segment.Start
+		return end > 0 && source[end-1] == '>' && bytes.HasSuffix(source[:end-1], label)
+	}
+	if prev, ok := n.PreviousSibling().(*ast.Text); ok && !prev.SoftLineBreak() && !prev.HardLineBreak() {
+		start := prev.Segment.Stop
+		return start < len(source) && source[start] == '<' && bytes.HasPrefix(source[start+1:], label)
+	}
+	if p := n.Parent(); p != nil && n.PreviousSibling() == nil && p.Type() == ast.TypeBlock && p.Lines().Len() > 0 {
+		start := p.Lines().At(0).Start
+		return start < len(source) && source[start] == '<'
+	}
+	return !bytes.HasPrefix(label, []byte("www."))
+}
+
+// renderInline renders the children of the node into a string with the render funcs of this renderer
+func (r *MarkdownRenderer) renderInline(source []byte, node ast.Node) string {
+	if r.funcs == nil {
+		r.funcs = funcsCollector{}
+		r.RegisterFuncs(r.funcs)
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		_ = ast.Walk(c, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if f, ok := r.funcs[n.Kind()]; ok {
+				return f(w, source, n, entering)
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+	_ = w.Flush()
+	return buf.String()
+}
+
+// tildesOf returns the tildes of the strikethrough as written in the source, or "~~" if not found
+func tildesOf(n *extast.Strikethrough, source []byte) string {
+	if t, ok := n.FirstChild().(*ast.Text); ok {
+		start := t.Segment.Start
+		if start >= 2 && string(source[start-2:start]) == "~~" {
+			return "~~"
+		}
+		if start >= 1 && source[start-1] == '~' {
+			return "~"
+		}
+	}
+	return "~~"
+}
+
+// padCell pads the cell to the width following the alignment
+func padCell(cell string, width int, align extast.Alignment) string {
+	space := width - displayWidth(cell)
+	switch align {
+	case extast.AlignRight:
+		return strings.Repeat(" ", space) + cell
+	case extast.AlignCenter:
+		return strings.Repeat(" ", space/2) + cell + strings.Repeat(" ", space-space/2)
+	default:
+		return cell + strings.Repeat(" ", space)
+	}
+}
+
+// delimiterCell returns the cell of the delimiter row for the alignment, e.g. ":---:"
+func delimiterCell(width int, align extast.Alignment) string {
+	switch align {
+	case extast.AlignLeft:
+		return ":" + strings.Repeat("-", width-1)
+	case extast.AlignRight:
+		return strings.Repeat("-", width-1) + ":"
+	case extast.AlignCenter:
+		return ":" + strings.Repeat("-", width-2) + ":"
+	default:
+		return strings.Repeat("-", width)
+	}
+}
+
+// displayWidth returns the width of the string in monospace fonts, counting east asian wide characters as two columns
+func displayWidth(s string) int {
+	var width int
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+			// combining marks and zero width characters
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isWide reports whether the rune is east asian wide or fullwidth
+func isWide(r rune) bool {
+	return r >= 0x1100 && r <= 0x115F || // hangul jamo
+		r >= 0x2E80 && r <= 0x303E || // cjk radicals, punctuation
+		r >= 0x3041 && r <= 0x33FF || // hiragana, katakana, cjk compatibility
+		r >= 0x3400 && r <= 0x4DBF || // cjk extension a
+		r >= 0x4E00 && r <= 0x9FFF || // cjk unified ideographs
+		r >= 0xA000 && r <= 0xA4CF || // yi
+		r >= 0xAC00 && r <= 0xD7A3 || // hangul syllables
+		r >= 0xF900 && r <= 0xFAFF || // cjk compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F || // cjk compatibility forms
+		r >= 0xFF00 && r <= 0xFF60 || // fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6 ||
+		r >= 0x1F300 && r <= 0x1F64F || // emoji
+		r >= 0x1F900 && r <= 0x1F9FF ||
+		r >= 0x20000 && r <= 0x3FFFD // cjk extension b and beyond
+}
@@ -7,7 +7,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
 )
 
 func TestMarkdownRenderer_renderHeading(t *testing.T) {
@@ -29,6 +34,11 @@ func TestMarkdownRenderer_renderHeading(t *testing.T) {
 		{
 			name:  "entering, level 1",
 			args:  args{node: genHeaderNode(1, false, false, false), entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n# ", err: false},
+		},
+		{
+			name:  "entering, level 1, first node",
+			args:  args{node: genHeaderNode(1, false, true, false), entering: true},
 			wants: wants{status: ast.WalkContinue, str: "# ", err: false},
 		},
 		{
@@ -39,7 +49,7 @@ func TestMarkdownRenderer_renderHeading(t *testing.T) {
 		{
 			name:  "entering, level 6",
 			args:  args{node: genHeaderNode(6, false, false, false), entering: true},
-			wants: wants{status: ast.WalkContinue, str: "###### ", err: false},
+			wants: wants{status: ast.WalkContinue, str: "\n###### ", err: false},
 		},
 		{
 			name:  "entering, blank previous lines",
@@ -86,6 +96,33 @@ func TestMarkdownRenderer_renderHeading(t *testing.T) {
 	}
 }
 
+func TestMarkdownRenderer_renderHeading_inDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "after a paragraph", source: "para\n# h", want: "para\n# h\n"},
+		{name: "after a list", source: "- a\n# h", want: "- a\n# h\n"},
+		{name: "in a list item", source: "- # h\n  text", want: "- # h\n  text"},
+		{name: "after a task in a list item", source: "- [ ] a\n  ### sub\n- b", want: "- [ ] a\n  ### sub\n- b"},
+		{name: "in a blockquote", source: "> # h\n> para", want: "> # h\n> para"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(
+				goldmark.WithExtensions(extension.GFM),
+				goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(NewMarkdownRenderer(), 1))),
+			)
+			var buf strings.Builder
+			assert.NoError(md.Convert([]byte(tt.source), &buf))
+			// the first block of a document is written after a blank line
+			assert.Equal(tt.want, strings.TrimLeft(buf.String(), "\n"))
+		})
+	}
+}
+
 func TestMarkdownRenderer_renderEmphasis(t *testing.T) {
 	type args struct {
 		node     ast.Node
@@ -211,7 +248,7 @@ func TestMarkdownRenderer_renderTaskCheckBox(t *testing.T) {
 
 func TestMarkdownRenderer_renderLink(t *testing.T) {
 	type args struct {
-		node     ast.Node
+		source   string
 		entering bool
 	}
 	type wants struct {
@@ -220,8 +257,6 @@ func TestMarkdownRenderer_renderLink(t *testing.T) {
 		err    bool
 	}
 
-	source := []byte("text")
-
 	tests := []struct {
 		name  string
 		args  args
@@ -229,13 +264,38 @@ func TestMarkdownRenderer_renderLink(t *testing.T) {
 	}{
 		{
 			name:  "entering true",
-			args:  args{node: genLinkNode([]byte("text"), []byte("destination")), entering: true},
-			wants: wants{status: ast.WalkContinue, str: "[text](destination)", err: false},
+			args:  args{source: "[text](destination)", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "[", err: false},
 		},
 		{
 			name:  "entering false",
-			args:  args{node: genLinkNode([]byte("text"), []byte("destination")), entering: false},
-			wants: wants{status: ast.WalkContinue, str: "", err: false},
+			args:  args{source: "[text](destination)", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "](destination)", err: false},
+		},
+		{
+			name:  "entering false, title",
+			args:  args{source: `[text](destination 'the "title"')`, entering: false},
+			wants: wants{status: ast.WalkContinue, str: `](destination 'the "title"')`, err: false},
+		},
+		{
+			name:  "entering false, escaped quote in title",
+			args:  args{source: `[text](destination "ti\"tle")`, entering: false},
+			wants: wants{status: ast.WalkContinue, str: `](destination "ti\"tle")`, err: false},
+		},
+		{
+			name:  "entering false, angle brackets",
+			args:  args{source: "[text](<my destination>)", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "](<my destination>)", err: false},
+		},
+		{
+			name:  "entering false, escaped parenthesis",
+			args:  args{source: "[text](a\\)b)", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "](a\\)b)", err: false},
+		},
+		{
+			name:  "entering false, reference",
+			args:  args{source: "[text][ref]\n\n[ref]: destination", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "](destination)", err: false},
 		},
 	}
 	for _, tt := range tests {
@@ -244,15 +304,74 @@ func TestMarkdownRenderer_renderLink(t *testing.T) {
 			r := NewMarkdownRenderer()
 			sb := new(strings.Builder)
 			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
 
-			got, err := r.renderLink(w, source, tt.args.node, tt.args.entering)
+			node := genParsedNode(source, ast.KindLink, 0)
+			got, err := r.renderLink(w, source, node, tt.args.entering)
 			if (err != nil) != tt.wants.err {
 				t.Errorf("MarkdownRenderer.renderLink() error = %v, wantErr %v", err, tt.wants.err)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.wants.status) {
-				t.Errorf("MarkdownRenderer.renderLink() = %v, want %v", got, tt.wants.status)
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderImage(t *testing.T) {
+	type args struct {
+		source   string
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering true",
+			args:  args{source: "![alt](img.png)", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "![", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "![alt](img.png)", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "](img.png)", err: false},
+		},
+		{
+			name:  "entering false, title",
+			args:  args{source: `![alt](img.png "title")`, entering: false},
+			wants: wants{status: ast.WalkContinue, str: `](img.png "title")`, err: false},
+		},
+		{
+			name:  "entering false, empty destination",
+			args:  args{source: "![alt](<>)", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "]()", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, ast.KindImage, 0)
+			got, err := r.renderImage(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderImage() error = %v, wantErr %v", err, tt.wants.err)
+				return
 			}
+			assert.Equal(tt.wants.status, got)
 
 			assert.NoError(w.Flush())
 			assert.Equal(tt.wants.str, sb.String())
@@ -262,7 +381,7 @@ func TestMarkdownRenderer_renderLink(t *testing.T) {
 
 func TestMarkdownRenderer_renderAutoLink(t *testing.T) {
 	type args struct {
-		node     ast.Node
+		source   string
 		entering bool
 	}
 	type wants struct {
@@ -271,8 +390,6 @@ func TestMarkdownRenderer_renderAutoLink(t *testing.T) {
 		err    bool
 	}
 
-	source := []byte("https://example.com")
-
 	tests := []struct {
 		name  string
 		args  args
@@ -280,14 +397,39 @@ func TestMarkdownRenderer_renderAutoLink(t *testing.T) {
 	}{
 		{
 			name:  "entering true",
-			args:  args{node: genAutoLinkNode(source), entering: true},
+			args:  args{source: "https://example.com", entering: true},
 			wants: wants{status: ast.WalkContinue, str: "https://example.com", err: false},
 		},
 		{
 			name:  "entering false",
-			args:  args{node: genAutoLinkNode(source), entering: false},
+			args:  args{source: "https://example.com", entering: false},
 			wants: wants{status: ast.WalkContinue, str: "", err: false},
 		},
+		{
+			name:  "entering, angle brackets",
+			args:  args{source: "<https://example.com>", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "<https://example.com>", err: false},
+		},
+		{
+			name:  "entering, angle brackets after text",
+			args:  args{source: "see <https://example.com>", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "<https://example.com>", err: false},
+		},
+		{
+			name:  "entering, bare before text",
+			args:  args{source: "https://example.com is bare", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "https://example.com", err: false},
+		},
+		{
+			name:  "entering, www",
+			args:  args{source: "see www.example.com", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "www.example.com", err: false},
+		},
+		{
+			name:  "entering, email",
+			args:  args{source: "<foo@example.com> is mine", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "<foo@example.com>", err: false},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -295,15 +437,15 @@ func TestMarkdownRenderer_renderAutoLink(t *testing.T) {
 			r := NewMarkdownRenderer()
 			sb := new(strings.Builder)
 			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
 
-			got, err := r.renderAutoLink(w, source, tt.args.node, tt.args.entering)
+			node := genParsedNode(source, ast.KindAutoLink, 0)
+			got, err := r.renderAutoLink(w, source, node, tt.args.entering)
 			if (err != nil) != tt.wants.err {
 				t.Errorf("MarkdownRenderer.renderAutoLink() error = %v, wantErr %v", err, tt.wants.err)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.wants.status) {
-				t.Errorf("MarkdownRenderer.renderAutoLink() = %v, want %v", got, tt.wants.status)
-			}
+			assert.Equal(tt.wants.status, got)
 
 			assert.NoError(w.Flush())
 			assert.Equal(tt.wants.str, sb.String())
@@ -351,8 +493,8 @@ func TestMarkdownRenderer_renderText(t *testing.T) {
 		},
 		{
 			name:  "entering, parent link",
-			args:  args{node: genTextNode(source, true, ast.NewLink()), entering: true},
-			wants: wants{status: ast.WalkContinue, str: "", err: false},
+			args:  args{node: genTextNode(source, false, ast.NewLink()), entering: true},
+			wants: wants{status: ast.WalkContinue, str: string(source), err: false},
 		},
 		{
 			name:  "entering, parent nil",
@@ -673,7 +815,7 @@ func TestMarkdownRenderer_renderListItem(t *testing.T) {
 
 func TestMarkdownRenderer_renderThematicBreak(t *testing.T) {
 	type args struct {
-		node     ast.Node
+		source   string
 		entering bool
 	}
 	type wants struct {
@@ -682,8 +824,6 @@ func TestMarkdownRenderer_renderThematicBreak(t *testing.T) {
 		err    bool
 	}
 
-	source := []byte("test text")
-
 	tests := []struct {
 		name  string
 		args  args
@@ -691,30 +831,50 @@ func TestMarkdownRenderer_renderThematicBreak(t *testing.T) {
 	}{
 		{
 			name:  "entering",
-			args:  args{node: genThematicBreakNode(), entering: true},
+			args:  args{source: "---", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n---", err: false},
+		},
+		{
+			name:  "entering, after paragraph",
+			args:  args{source: "text\n\n---", entering: true},
 			wants: wants{status: ast.WalkContinue, str: "\n\n---", err: false},
 		},
+		{
+			name:  "entering, in blockquote",
+			args:  args{source: "> a\n>\n> ---", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n>\n> ---", err: false},
+		},
+		{
+			name:  "entering, in list item",
+			args:  args{source: "- a\n\n  ---", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n  ---", err: false},
+		},
+		{
+			name:  "entering, first in list item",
+			args:  args{source: "- ***", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "***", err: false},
+		},
 		{
 			name:  "entering false",
-			args:  args{node: genThematicBreakNode(), entering: false},
+			args:  args{source: "---", entering: false},
 			wants: wants{status: ast.WalkContinue, str: "", err: false},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert := assert.New(t)
-			r := &MarkdownRenderer{}
+			r := NewMarkdownRenderer()
 			sb := new(strings.Builder)
 			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
 
-			got, err := r.renderThematicBreak(w, source, tt.args.node, tt.args.entering)
+			node := genParsedNode(source, ast.KindThematicBreak, 0)
+			got, err := r.renderThematicBreak(w, source, node, tt.args.entering)
 			if (err != nil) != tt.wants.err {
 				t.Errorf("MarkdownRenderer.renderThematicBreak() error = %v, wantErr %v", err, tt.wants.err)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.wants.status) {
-				t.Errorf("MarkdownRenderer.renderThematicBreak() = %v, want %v", got, tt.wants.status)
-			}
+			assert.Equal(tt.wants.status, got)
 
 			assert.NoError(w.Flush())
 			assert.Equal(tt.wants.str, sb.String())
@@ -722,6 +882,33 @@ func TestMarkdownRenderer_renderThematicBreak(t *testing.T) {
 	}
 }
 
+func TestMarkdownRenderer_renderThematicBreak_inDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "after a paragraph", source: "para\n\n---", want: "para\n\n---"},
+		{name: "in a blockquote", source: "> a\n>\n> ---\n", want: "> a\n>\n> ---"},
+		{name: "in a nested blockquote", source: "> > a\n> >\n> > ---\n> > b", want: "> > a\n> >\n> > ---\n> > b"},
+		{name: "in a list item", source: "- a\n\n  ---\n- b", want: "- a\n\n  ---\n\n- b"},
+		{name: "first in a list item", source: "- ***\n- b", want: "- ***\n- b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(
+				goldmark.WithExtensions(extension.GFM),
+				goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(NewMarkdownRenderer(), 1))),
+			)
+			var buf strings.Builder
+			assert.NoError(md.Convert([]byte(tt.source), &buf))
+			// the first block of a document is written after a blank line
+			assert.Equal(tt.want, strings.TrimLeft(buf.String(), "\n"))
+		})
+	}
+}
+
 func TestMarkdownRenderer_renderCodeSpan(t *testing.T) {
 	type args struct {
 		node     ast.Node
@@ -772,3 +959,550 @@ func TestMarkdownRenderer_renderCodeSpan(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdownRenderer_renderCodeSpan_inDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "plain", source: "use `a` here", want: "use `a` here"},
+		{name: "backtick inside", source: "use `` a`b `` here", want: "use ``a`b`` here"},
+		{name: "backtick at the edge", source: "use `` `a `` here", want: "use `` `a `` here"},
+		{name: "spaces kept", source: "use `  a  ` here", want: "use `  a  ` here"},
+		{name: "only spaces", source: "use ` ` here", want: "use ` ` here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(
+				goldmark.WithExtensions(extension.GFM),
+				goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(NewMarkdownRenderer(), 1))),
+			)
+			var buf strings.Builder
+			assert.NoError(md.Convert([]byte(tt.source), &buf))
+			// the first block of a document is written after a blank line
+			assert.Equal(tt.want, strings.TrimLeft(buf.String(), "\n"))
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderBlockquote(t *testing.T) {
+	type args struct {
+		source   string
+		nth      int
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering",
+			args:  args{source: "> quote", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n> ", err: false},
+		},
+		{
+			name:  "entering, after paragraph",
+			args:  args{source: "text\n\n> quote", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n> ", err: false},
+		},
+		{
+			name:  "entering, nested after paragraph",
+			args:  args{source: "> text\n>\n> > nested", nth: 1, entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n>\n> > ", err: false},
+		},
+		{
+			name:  "entering, in list item",
+			args:  args{source: "- item\n\n  > quote", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n  > ", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "> quote", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, ast.KindBlockquote, tt.args.nth)
+			got, err := r.renderBlockquote(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderBlockquote() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderCodeBlock(t *testing.T) {
+	type args struct {
+		source   string
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering",
+			args:  args{source: "    code\n    more code", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n    code\n    more code", err: false},
+		},
+		{
+			name:  "entering, blank line in code",
+			args:  args{source: "text\n\n    code\n\n      indented more", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n    code\n\n      indented more", err: false},
+		},
+		{
+			name:  "entering, in blockquote",
+			args:  args{source: ">     code\n>     more code", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "    code\n>     more code", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "    code", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, ast.KindCodeBlock, 0)
+			got, err := r.renderCodeBlock(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderCodeBlock() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderFencedCodeBlock(t *testing.T) {
+	type args struct {
+		source   string
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering",
+			args:  args{source: "```\ncode\n```", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n```\ncode\n```", err: false},
+		},
+		{
+			name:  "entering, info string",
+			args:  args{source: "```go {linenos=true}\nfunc main() {}\n```", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n```go {linenos=true}\nfunc main() {}\n```", err: false},
+		},
+		{
+			name:  "entering, long tilde fence",
+			args:  args{source: "~~~~~\n```\n~~~\n~~~~~", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n~~~~~\n```\n~~~\n~~~~~", err: false},
+		},
+		{
+			name:  "entering, unclosed fence",
+			args:  args{source: "````\ncode", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n````\ncode\n````", err: false},
+		},
+		{
+			name:  "entering, empty",
+			args:  args{source: "```\n```", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n```\n```", err: false},
+		},
+		{
+			name:  "entering, in list item",
+			args:  args{source: "- [ ] todo\n\n  ```sh\n  make\n\n  make test\n  ```", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "\n\n  ```sh\n  make\n\n  make test\n  ```", err: false},
+		},
+		{
+			name:  "entering, in nested blockquote",
+			args:  args{source: "> > ```\n> > code\n> > ```", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "```\n> > code\n> > ```", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "```\ncode\n```", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, ast.KindFencedCodeBlock, 0)
+			got, err := r.renderFencedCodeBlock(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderFencedCodeBlock() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderTable(t *testing.T) {
+	type args struct {
+		source   string
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering, padded cells",
+			args:  args{source: "|a|long header|\n|-|-|\n|longer cell|b|", entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "| a           | long header |\n| ----------- | ----------- |\n| longer cell | b           |", err: false},
+		},
+		{
+			name:  "entering, alignments",
+			args:  args{source: "| l | c | r |\n|:-|:-:|-:|\n| 1 | 2 | 3 |", entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "| l   |  c  |   r |\n| :-- | :-: | --: |\n| 1   |  2  |   3 |", err: false},
+		},
+		{
+			name:  "entering, east asian wide characters and inlines",
+			args:  args{source: "| 項目 | note |\n| --- | --- |\n| a | **b** \\| c |", entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "| 項目 | note       |\n| ---- | ---------- |\n| a    | **b** \\| c |", err: false},
+		},
+		{
+			name:  "entering, missing cells",
+			args:  args{source: "| a | b |\n| --- | --- |\n| 1 |", entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "| a   | b   |\n| --- | --- |\n| 1   |     |", err: false},
+		},
+		{
+			name:  "entering, in blockquote",
+			args:  args{source: "> quote\n>\n> | a |\n> | - |\n> | 1 |", entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "\n>\n> | a   |\n> | --- |\n> | 1   |", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "| a |\n| - |", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, extast.KindTable, 0)
+			got, err := r.renderTable(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderTable() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderStrikethrough(t *testing.T) {
+	type args struct {
+		source   string
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering",
+			args:  args{source: "~~done~~", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "~~", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{source: "~~done~~", entering: false},
+			wants: wants{status: ast.WalkContinue, str: "~~", err: false},
+		},
+		{
+			name:  "entering, single tilde",
+			args:  args{source: "~done~", entering: true},
+			wants: wants{status: ast.WalkContinue, str: "~", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.args.source)
+
+			node := genParsedNode(source, extast.KindStrikethrough, 0)
+			got, err := r.renderStrikethrough(w, source, node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderStrikethrough() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			assert.Equal(tt.wants.status, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderText_hardLineBreak(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		nth    int
+		want   string
+	}{
+		{name: "trailing spaces", source: "foo  \nbar", want: "foo  \n"},
+		{name: "backslash", source: "foo\\\nbar", want: "foo\\\n"},
+		{name: "in list item", source: "- foo\\\n  bar", want: "foo\\\n  "},
+		{name: "in blockquote", source: "> foo  \n> bar", want: "foo  \n> "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.source)
+
+			node := genParsedNode(source, ast.KindText, tt.nth)
+			got, err := r.renderText(w, source, node, true)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderHTMLBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		nth    int
+		want   string
+	}{
+		{name: "details", source: "<details>\n<summary>folded</summary>", want: "\n\n<details>\n<summary>folded</summary>"},
+		{name: "closing tag after blank line", source: "<details>\n\nbody\n\n</details>", nth: 1, want: "\n\n</details>"},
+		{name: "comment with closure", source: "<!--\na comment\n-->", want: "\n\n<!--\na comment\n-->"},
+		{name: "blank line in pre", source: "<pre>\ncode\n\nmore\n</pre>", want: "\n\n<pre>\ncode\n\nmore\n</pre>"},
+		{name: "in list item", source: "- <div>\n  html\n  </div>", want: "<div>\n  html\n  </div>"},
+		{name: "in blockquote", source: "> <!-- c\n> more -->", want: "<!-- c\n> more -->"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.source)
+
+			node := genParsedNode(source, ast.KindHTMLBlock, tt.nth)
+			got, err := r.renderHTMLBlock(w, source, node, true)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderRawHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "tag", source: "a <b>bold</b>", want: "<b>"},
+		{name: "comment", source: "a <!-- comment -->", want: "<!-- comment -->"},
+		{name: "spanning lines", source: "a <span\nclass=\"x\">", want: "<span\nclass=\"x\">"},
+		{name: "spanning lines in list item", source: "- a <span\n  class=\"x\">", want: "<span\n  class=\"x\">"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+			source := []byte(tt.source)
+
+			node := genParsedNode(source, ast.KindRawHTML, 0)
+			got, err := r.renderRawHTML(w, source, node, true)
+			assert.NoError(err)
+			assert.Equal(ast.WalkSkipChildren, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderString(t *testing.T) {
+	tests := []struct {
+		name string
+		node *ast.String
+		want string
+	}{
+		{name: "plain", node: ast.NewString([]byte("plain text")), want: "plain text"},
+		{name: "escaped", node: ast.NewString([]byte("*not* [a](link) <b>")), want: `\*not\* \[a\](link) \<b\>`},
+		{name: "code", node: func() *ast.String { s := ast.NewString([]byte("*code*")); s.SetCode(true); return s }(), want: "*code*"},
+		{name: "raw", node: func() *ast.String { s := ast.NewString([]byte("<b>")); s.SetRaw(true); return s }(), want: "<b>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewMarkdownRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderString(w, nil, tt.node, true)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_options(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []renderer.Option
+		source string
+		want   string
+	}{
+		{
+			name:   "defaults",
+			source: "* a\n  * b\n\n3) c\n3) d\n\n_em_ and __strong__",
+			want:   "* a\n  * b\n\n3) c\n4) d\n\n*em* and **strong**",
+		},
+		{
+			name:   "bullet marker",
+			opts:   []renderer.Option{WithBulletMarker('-')},
+			source: "* a\n  + b",
+			want:   "- a\n  - b",
+		},
+		{
+			name:   "bullet marker, next list keeps its marker",
+			opts:   []renderer.Option{WithBulletMarker('-')},
+			source: "* a\n+ b",
+			want:   "- a\n+ b",
+		},
+		{
+			name:   "ordered list, one",
+			opts:   []renderer.Option{WithOrderedListStyle(ORDEREDLISTSTYLE_ONE)},
+			source: "1. a\n2. b\n3. c",
+			want:   "1. a\n1. b\n1. c",
+		},
+		{
+			name:   "emphasis marker",
+			opts:   []renderer.Option{WithEmphasisMarker('_')},
+			source: "*em* **strong** in*word*",
+			want:   "_em_ __strong__ in*word*",
+		},
+		{
+			name:   "list indent",
+			opts:   []renderer.Option{WithListIndent(4)},
+			source: "- a\n  - b\n\n  para\n\n9. c\n10. d\n    - e",
+			want:   "-   a\n    -   b\n\n    para\n\n9.  c\n10. d\n    -   e",
+		},
+		{
+			name:   "blank line between blocks",
+			opts:   []renderer.Option{WithBlankLineBetweenBlocks(true)},
+			source: "# h\npara\n- a\n- b\n  ```\n  code\n  ```",
+			want:   "# h\n\npara\n\n- a\n- b\n  ```\n  code\n  ```",
+		},
+		{
+			name:   "line ending",
+			opts:   []renderer.Option{WithLineEnding(LINEENDING_CRLF)},
+			source: "# h\n\n- a\n  b",
+			want:   "# h\r\n\r\n- a\r\n  b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(
+				goldmark.WithExtensions(extension.GFM),
+				goldmark.WithRendererOptions(append([]renderer.Option{
+					renderer.WithNodeRenderers(util.Prioritized(NewMarkdownRenderer(), 1)),
+				}, tt.opts...)...),
+			)
+			var buf strings.Builder
+			assert.NoError(md.Convert([]byte(tt.source), &buf))
+			// the first block of a document is written after a blank line
+			assert.Equal(tt.want, strings.TrimLeft(buf.String(), "\r\n"))
+		})
+	}
+}
@@ -1,18 +1,13 @@
 package renderer
 
 import (
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
 	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
 )
 
-// safeGroundParent returns the parent of the parent of the node ensuring nil safe.
-func safeGroundParent(n ast.Node) ast.Node {
-	if n.Parent() == nil {
-		return nil
-	}
-	return n.Parent().Parent()
-}
-
 func genHeaderNode(level int, setBlankSpacePreviousLines bool, isFirstNode, isLastNode bool) ast.Node {
 	h := ast.NewHeading(level)
 	h.SetBlankPreviousLines(setBlankSpacePreviousLines)
@@ -37,30 +32,6 @@ func genTextNode(text []byte, setSoftLineBreak bool, parent ast.Node) ast.Node {
 	return t
 }
 
-func genLinkNode(text, destination []byte) ast.Node {
-	nl := ast.NewLink()
-	nl.Destination = destination
-
-	// segment
-	t := ast.NewText()
-	t.Segment.Start = 0
-	t.Segment.Stop = len(text)
-	nl.AppendChild(nl, t)
-
-	return nl
-}
-
-func genAutoLinkNode(text []byte) ast.Node {
-	// segment
-	t := ast.NewText()
-	t.Segment.Start = 0
-	t.Segment.Stop = len(text)
-
-	al := ast.NewAutoLink(ast.AutoLinkURL, t)
-
-	return al
-}
-
 func genTaskCheckBoxNode(checked bool) ast.Node {
 	return extast.NewTaskCheckBox(checked)
 }
@@ -143,3 +114,20 @@ func genThematicBreakNode() ast.Node {
 func genCodeSpan() ast.Node {
 	return ast.NewCodeSpan()
 }
+
+// genParsedNode parses the source and returns the nth node of the kind in document order, counting from 0
+func genParsedNode(source []byte, kind ast.NodeKind, nth int) ast.Node {
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(source))
+	var found ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == kind {
+			if nth == 0 {
+				found = n
+				return ast.WalkStop, nil
+			}
+			nth--
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
@@ -0,0 +1,116 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	ErrHeadingNotFound  = errors.New("heading not found")
+	ErrAmbiguousHeading = errors.New("heading is ambiguous")
+)
+
+// section is a heading and the lines up to the next heading of the same or upper level
+type section struct {
+	heading   *ast.Heading
+	start     int // beginning of the heading line
+	bodyStart int // end of the heading line
+	end       int // beginning of the next section, or the end of the source
+}
+
+// SelectHeadings returns the top level headings in the document that match the heading at the same level.
+// In substring mode, exact matches are returned alone if any.
+func (heading Heading) SelectHeadings(doc ast.Node, source []byte) []*ast.Heading {
+	var matched, exact []*ast.Heading
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		h, ok := c.(*ast.Heading)
+		if !ok || h.Level != heading.Level || h.Lines().Len() == 0 {
+			continue
+		}
+		text := string(h.Text(source))
+		if heading.Matches(text) {
+			matched = append(matched, h)
+			if strings.TrimSpace(text) == heading.Text {
+				exact = append(exact, h)
+			}
+		}
+	}
+	if heading.Match == MATCHMODE_SUBSTRING && len(exact) > 0 {
+		return exact
+	}
+	return matched
+}
+
+// findHeading returns the heading in the document that the selector selects.
+// It returns ErrHeadingNotFound if there is none, and ErrAmbiguousHeading if there are more than one.
+func findHeading(doc ast.Node, source []byte, heading HeadingSelector) (*ast.Heading, error) {
+	matched := heading.SelectHeadings(doc, source)
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrHeadingNotFound, heading)
+	case 1:
+		return matched[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %d headings match %s", ErrAmbiguousHeading, len(matched), heading)
+	}
+}
+
+// ReplaceSection replaces the content of the section, including its subsections, with the text
+func (gmw *GoldmarkWrapper) ReplaceSection(source []byte, heading HeadingSelector, text string) ([]byte, error) {
+	d := gmw.NewDocument(source)
+	if err := d.ReplaceSection(heading, text); err != nil {
+		return nil, err
+	}
+	return d.Bytes(), nil
+}
+
+// DeleteSection deletes the heading and its content, including its subsections
+func (gmw *GoldmarkWrapper) DeleteSection(source []byte, heading HeadingSelector) ([]byte, error) {
+	d := gmw.NewDocument(source)
+	if err := d.DeleteSection(heading); err != nil {
+		return nil, err
+	}
+	return d.Bytes(), nil
+}
+
+// MoveSection moves the section, including its subsections, right after the section of the other heading
+func (gmw *GoldmarkWrapper) MoveSection(source []byte, heading, after HeadingSelector) ([]byte, error) {
+	d := gmw.NewDocument(source)
+	s, err := d.section(heading)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.DeleteSection(heading); err != nil {
+		return nil, err
+	}
+	return gmw.AppendToSection(d.Bytes(), after, string(source[s.start:s.end]))
+}
+
+// AppendToSection appends the text to the end of the section, after its subsections
+func (gmw *GoldmarkWrapper) AppendToSection(source []byte, heading HeadingSelector, text string) ([]byte, error) {
+	d := gmw.NewDocument(source)
+	if err := d.AppendToSection(heading, text); err != nil {
+		return nil, err
+	}
+	return d.Bytes(), nil
+}
+
+// CreateSectionIfMissing appends the heading to the end of the source unless the source has it
+func (gmw *GoldmarkWrapper) CreateSectionIfMissing(source []byte, heading Heading) ([]byte, error) {
+	d := gmw.NewDocument(source)
+	_, err := d.section(heading)
+	switch {
+	case err == nil:
+		return source, nil
+	case errors.Is(err, ErrHeadingNotFound):
+		if err := d.splice(len(source), len(source), heading.String()); err != nil {
+			return nil, err
+		}
+		return d.Bytes(), nil
+	default:
+		return nil, err
+	}
+}
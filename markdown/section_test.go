@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeading_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		heading Heading
+		text    string
+		want    bool
+	}{
+		{name: "substring", heading: NewHeading(2, "todo"), text: "wanttodos", want: true},
+		{name: "substring, not contained", heading: NewHeading(2, "memo"), text: "todos", want: false},
+		{name: "exact", heading: Heading{Level: 2, Text: "todos", Match: MATCHMODE_EXACT}, text: "todos", want: true},
+		{name: "exact, substring", heading: Heading{Level: 2, Text: "todos", Match: MATCHMODE_EXACT}, text: "wanttodos", want: false},
+		{name: "slug", heading: Heading{Level: 2, Text: "deploy-notes", Match: MATCHMODE_SLUG}, text: "deploy notes", want: true},
+		{name: "slug, different", heading: Heading{Level: 2, Text: "deploy-notes", Match: MATCHMODE_SLUG}, text: "deploy", want: false},
+		{name: "slug, github style", heading: Heading{Level: 2, Text: "deploy-notes", Match: MATCHMODE_SLUG}, text: "deploy  notes", want: false},
+		{name: "slug, gitlab style", heading: Heading{Level: 2, Text: "deploy-notes", Match: MATCHMODE_SLUG, SlugStyle: SLUGSTYLE_GITLAB}, text: "deploy  notes", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.heading.Matches(tt.text))
+		})
+	}
+}
+
+func TestGoldmarkWrapper_sections(t *testing.T) {
+	source := "# memo\n\n## todos\n\n- [ ] todo\n\n## wanttodos\n\n- [ ] want\n\n## memos\n\n### deploy notes\n\nnotes\n"
+
+	tests := []struct {
+		name    string
+		edit    func(gmw *GoldmarkWrapper, source []byte) ([]byte, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "replace section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.ReplaceSection(source, NewHeading(2, "memos"), "new memo")
+			},
+			want: "# memo\n\n## todos\n\n- [ ] todo\n\n## wanttodos\n\n- [ ] want\n\n## memos\n\nnew memo\n",
+		},
+		{
+			name: "replace section, exact match wins over substring",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.ReplaceSection(source, NewHeading(2, "todos"), "- [ ] new")
+			},
+			want: "# memo\n\n## todos\n\n- [ ] new\n\n## wanttodos\n\n- [ ] want\n\n## memos\n\n### deploy notes\n\nnotes\n",
+		},
+		{
+			name: "replace section, ambiguous substring",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.ReplaceSection(source, NewHeading(2, "todo"), "- [ ] new")
+			},
+			wantErr: ErrAmbiguousHeading,
+		},
+		{
+			name: "delete section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.DeleteSection(source, Heading{Level: 2, Text: "wanttodos", Match: MATCHMODE_EXACT})
+			},
+			want: "# memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n### deploy notes\n\nnotes\n",
+		},
+		{
+			name: "delete last section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.DeleteSection(source, Heading{Level: 3, Text: "deploy-notes", Match: MATCHMODE_SLUG})
+			},
+			want: "# memo\n\n## todos\n\n- [ ] todo\n\n## wanttodos\n\n- [ ] want\n\n## memos\n",
+		},
+		{
+			name: "delete section, not found",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.DeleteSection(source, NewHeading(2, "deploy notes"))
+			},
+			wantErr: ErrHeadingNotFound,
+		},
+		{
+			name: "move section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.MoveSection(source, NewHeading(2, "todos"), NewHeading(2, "memos"))
+			},
+			want: "# memo\n\n## wanttodos\n\n- [ ] want\n\n## memos\n\n### deploy notes\n\nnotes\n\n## todos\n\n- [ ] todo\n",
+		},
+		{
+			name: "move section into its own section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.MoveSection(source, NewHeading(2, "memos"), NewHeading(3, "deploy notes"))
+			},
+			wantErr: ErrHeadingNotFound,
+		},
+		{
+			name: "create missing section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.CreateSectionIfMissing(source, NewHeading(2, "links"))
+			},
+			want: source + "\n## links\n",
+		},
+		{
+			name: "create existing section",
+			edit: func(gmw *GoldmarkWrapper, source []byte) ([]byte, error) {
+				return gmw.CreateSectionIfMissing(source, NewHeading(2, "memos"))
+			},
+			want: source,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := tt.edit(NewGoldmarkWrapper(), []byte(source))
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// HeadingSelector selects headings in a document. Heading and Selector are the selectors.
+type HeadingSelector interface {
+	// SelectHeadings returns the headings in the document that the selector points at, in document order
+	SelectHeadings(doc ast.Node, source []byte) []*ast.Heading
+	String() string
+}
+
+// Selector selects a heading by the path of headings leading to it.
+//
+//	memos > deploy > notes  "notes" right under "deploy" right under "memos"
+//	memos/2                 the second heading right under "memos"
+//	## todos                "todos" at level 2
+//
+// Each step matches headings that contain the text, preferring the ones that are exactly the text.
+// The first step matches headings at any depth, and the following ones match headings right under the previous one.
+type Selector struct {
+	steps []selectorStep
+}
+
+type selectorStep struct {
+	level   int // 0 for any level
+	text    string
+	indices []int // 1-based positions of the headings to descend into after the text matches
+}
+
+var selectorStepRegex = regexp.MustCompile(`^(#*)\s*(.*?)((?:/\d+)*)$`)
+
+// ParseSelector parses the selector. e.g. `memos > deploy > notes`, `memos/2`
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range strings.Split(s, ">") {
+		m := selectorStepRegex.FindStringSubmatch(strings.TrimSpace(part))
+		step := selectorStep{level: len(m[1]), text: strings.TrimSpace(m[2])}
+		if step.text == "" {
+			return Selector{}, fmt.Errorf("invalid selector %q: a step has no heading text", s)
+		}
+		for _, index := range strings.Split(m[3], "/")[1:] {
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 1 {
+				return Selector{}, fmt.Errorf("invalid selector %q: positions start from 1", s)
+			}
+			step.indices = append(step.indices, n)
+		}
+		sel.steps = append(sel.steps, step)
+	}
+	return sel, nil
+}
+
+func (sel Selector) String() string {
+	var steps []string
+	for _, step := range sel.steps {
+		s := step.text
+		if step.level > 0 {
+			s = strings.Repeat("#", step.level) + " " + s
+		}
+		for _, n := range step.indices {
+			s += "/" + strconv.Itoa(n)
+		}
+		steps = append(steps, s)
+	}
+	return strings.Join(steps, " > ")
+}
+
+// outlineEntry is a top level heading and the index of its parent heading in the outline, or -1 for the document
+type outlineEntry struct {
+	heading *ast.Heading
+	parent  int
+}
+
+// outlineOf returns the top level headings of the document with their parents
+func outlineOf(doc ast.Node) []outlineEntry {
+	var outline []outlineEntry
+	var stack []int
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		h, ok := c.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		for len(stack) > 0 && outline[stack[len(stack)-1]].heading.Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		outline = append(outline, outlineEntry{heading: h, parent: parent})
+		stack = append(stack, len(outline)-1)
+	}
+	return outline
+}
+
+// SelectHeadings returns the headings at the end of the path. It may return more than one when steps are ambiguous.
+func (sel Selector) SelectHeadings(doc ast.Node, source []byte) []*ast.Heading {
+	outline := outlineOf(doc)
+
+	var current []int // indices in the outline selected so far
+	for i, step := range sel.steps {
+		var matched, exact []int
+		for j, e := range outline {
+			if i > 0 && !slices.Contains(current, e.parent) {
+				continue
+			}
+			if step.level > 0 && e.heading.Level != step.level {
+				continue
+			}
+			text := strings.TrimSpace(string(e.heading.Text(source)))
+			if strings.Contains(text, step.text) {
+				matched = append(matched, j)
+				if text == step.text {
+					exact = append(exact, j)
+				}
+			}
+		}
+		if len(exact) > 0 {
+			matched = exact
+		}
+
+		for _, n := range step.indices {
+			var children []int
+			for _, parent := range matched {
+				var count int
+				for j, e := range outline {
+					if e.parent == parent {
+						count++
+						if count == n {
+							children = append(children, j)
+							break
+						}
+					}
+				}
+			}
+			matched = children
+		}
+		current = matched
+	}
+
+	var headings []*ast.Heading
+	for _, j := range current {
+		headings = append(headings, outline[j].heading)
+	}
+	return headings
+}
@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "text", input: "memos", want: "memos"},
+		{name: "path", input: "memos>deploy >  notes", want: "memos > deploy > notes"},
+		{name: "level", input: "##todos", want: "## todos"},
+		{name: "position", input: "memos/2/1", want: "memos/2/1"},
+		{name: "empty step", input: "memos >", wantErr: true},
+		{name: "position from zero", input: "memos/0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := ParseSelector(tt.input)
+			if tt.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, got.String())
+		})
+	}
+}
+
+func TestSelector_sections(t *testing.T) {
+	source := "# memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n### deploy\n\n#### notes\n\napi\n\n### release\n\n#### notes\n\nweb\n"
+
+	tests := []struct {
+		name     string
+		selector string
+		text     string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "nested path",
+			selector: "memos > release > notes",
+			text:     "done",
+			want:     "# memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n### deploy\n\n#### notes\n\napi\n\n### release\n\n#### notes\n\ndone\n",
+		},
+		{
+			name:     "position",
+			selector: "memos/2",
+			text:     "done",
+			want:     "# memo\n\n## todos\n\n- [ ] todo\n\n## memos\n\n### deploy\n\n#### notes\n\napi\n\n### release\n\ndone\n",
+		},
+		{
+			name:     "level",
+			selector: "## todos",
+			text:     "- [ ] new",
+			want:     "# memo\n\n## todos\n\n- [ ] new\n\n## memos\n\n### deploy\n\n#### notes\n\napi\n\n### release\n\n#### notes\n\nweb\n",
+		},
+		{
+			name:     "not a direct child",
+			selector: "memos > notes",
+			wantErr:  ErrHeadingNotFound,
+		},
+		{
+			name:     "ambiguous",
+			selector: "notes",
+			wantErr:  ErrAmbiguousHeading,
+		},
+		{
+			name:     "wrong level",
+			selector: "### todos",
+			wantErr:  ErrHeadingNotFound,
+		},
+		{
+			name:     "position out of range",
+			selector: "memos/3",
+			wantErr:  ErrHeadingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			sel, err := ParseSelector(tt.selector)
+			assert.NoError(err)
+			got, err := NewGoldmarkWrapper().ReplaceSection([]byte(source), sel, tt.text)
+			if tt.wantErr != nil {
+				assert.ErrorIs(err, tt.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, string(got))
+		})
+	}
+}
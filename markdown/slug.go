@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// SlugStyle is the markdown viewer whose heading anchors links are generated for
+type SlugStyle string
+
+const (
+	SLUGSTYLE_GITHUB SlugStyle = "github"
+	SLUGSTYLE_GITLAB SlugStyle = "gitlab"
+	SLUGSTYLE_VSCODE SlugStyle = "vscode"
+)
+
+var (
+	// everything but letters, marks, numbers, connector punctuations such as "_", spaces and hyphens
+	nonSlugCharRegex = regexp.MustCompile(`[^\p{L}\p{M}\p{N}\p{Pc} -]`)
+	hyphensRegex     = regexp.MustCompile(`-+`)
+	whitespacesRegex = regexp.MustCompile(`\s+`)
+	// punctuations that VS Code removes, including full-width ones
+	vscodePunctRegex = regexp.MustCompile("[\\]\\[!/'\"#$%&()*+,.:;<=>?@\\\\^{|}~`。，、；：？！…—·ˉ¨‘’“”々～‖∶＂＇｀｜〃〔〕〈〉《》「」『』．〖〗【】（）［］｛｝]")
+)
+
+var slugFuncs = map[SlugStyle]func(string) string{
+	SLUGSTYLE_GITHUB: func(text string) string {
+		text = nonSlugCharRegex.ReplaceAllString(strings.ToLower(text), "")
+		return strings.ReplaceAll(text, " ", "-")
+	},
+	SLUGSTYLE_GITLAB: func(text string) string {
+		text = nonSlugCharRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(text)), "")
+		return hyphensRegex.ReplaceAllString(strings.ReplaceAll(text, " ", "-"), "-")
+	},
+	SLUGSTYLE_VSCODE: func(text string) string {
+		text = whitespacesRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(text)), "-")
+		return strings.Trim(vscodePunctRegex.ReplaceAllString(text, ""), "-")
+	},
+}
+
+// Slugger generates the anchors of the headings in a document.
+// A slug seen before in the document gets a suffix, -1, -2 and so on, as the viewers do.
+type Slugger struct {
+	slugify     func(string) string
+	occurrences map[string]int
+}
+
+// NewSlugger returns a slugger for a document. An empty style is SLUGSTYLE_GITHUB.
+func NewSlugger(style SlugStyle) (*Slugger, error) {
+	if style == "" {
+		style = SLUGSTYLE_GITHUB
+	}
+	slugify, ok := slugFuncs[style]
+	if !ok {
+		return nil, fmt.Errorf("unsupported slug style: %s", style)
+	}
+	return &Slugger{slugify: slugify, occurrences: map[string]int{}}, nil
+}
+
+// Slug returns the slug of the next heading in the document
+func (s *Slugger) Slug(text string) string {
+	slug := s.slugify(text)
+	result := slug
+	for {
+		if _, ok := s.occurrences[result]; !ok {
+			break
+		}
+		s.occurrences[slug]++
+		result = slug + "-" + strconv.Itoa(s.occurrences[slug])
+	}
+	s.occurrences[result] = 0
+	return result
+}
+
+// HeadingSlugs returns the slugs of all the headings in the document, including the ones in lists and blockquotes
+func (d *Document) HeadingSlugs(s *Slugger) map[ast.Node]string {
+	slugs := map[ast.Node]string{}
+	ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			slugs[h] = s.Slug(string(h.Text(d.source)))
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return slugs
+}
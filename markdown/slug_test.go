@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugger_Slug(t *testing.T) {
+	tests := []struct {
+		name  string
+		style SlugStyle
+		texts []string
+		want  []string
+	}{
+		{
+			name:  "github",
+			style: SLUGSTYLE_GITHUB,
+			texts: []string{"Deploy Notes", "API (v2): users/list", "a  -  b", "日本語の見出し！", "snake_case"},
+			want:  []string{"deploy-notes", "api-v2-userslist", "a-----b", "日本語の見出し", "snake_case"},
+		},
+		{
+			name:  "github by default",
+			texts: []string{"Deploy Notes"},
+			want:  []string{"deploy-notes"},
+		},
+		{
+			name:  "gitlab",
+			style: SLUGSTYLE_GITLAB,
+			texts: []string{"Deploy Notes", "API (v2): users/list", "a  -  b"},
+			want:  []string{"deploy-notes", "api-v2-userslist", "a-b"},
+		},
+		{
+			name:  "vscode",
+			style: SLUGSTYLE_VSCODE,
+			texts: []string{"Deploy Notes", "API (v2): users/list", "a  -  b", "「日本語」の見出し"},
+			want:  []string{"deploy-notes", "api-v2-userslist", "a---b", "日本語の見出し"},
+		},
+		{
+			name:  "duplicates",
+			style: SLUGSTYLE_GITHUB,
+			texts: []string{"memo", "memo", "memo-1", "memo"},
+			want:  []string{"memo", "memo-1", "memo-1-1", "memo-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			s, err := NewSlugger(tt.style)
+			assert.NoError(err)
+			var got []string
+			for _, text := range tt.texts {
+				got = append(got, s.Slug(text))
+			}
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestNewSlugger_unsupported(t *testing.T) {
+	_, err := NewSlugger("bitbucket")
+	assert.Error(t, err)
+}
+
+func TestDocument_HeadingSlugs(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("# memo\n\n## Todo *list*\n\n> ## memo\n\n## `code` memo\n")
+	d := NewGoldmarkWrapper().NewDocument(source)
+	s, err := NewSlugger(SLUGSTYLE_GITHUB)
+	assert.NoError(err)
+
+	slugs := d.HeadingSlugs(s)
+	headings := d.HeadingsByLevel(2)
+	quoted := headings[0].NextSibling().FirstChild()
+	assert.Equal("todo-list", slugs[headings[0]])
+	assert.Equal("memo-1", slugs[quoted])
+	assert.Equal("code-memo", slugs[headings[1]])
+}
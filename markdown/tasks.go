@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+)
+
+// PruneCheckedTasks removes checked task items from the lists in nodes, and returns the nodes left and the nodes removed.
+// A checked item is kept while any of its nested tasks is still open, and lists left empty are removed.
+func PruneCheckedTasks(nodes []ast.Node) ([]ast.Node, []ast.Node) {
+	return pruneTasks(nodes, func(task *extast.TaskCheckBox) bool {
+		return task == nil || !task.IsChecked
+	})
+}
+
+// PruneOpenTasks is the counterpart of PruneCheckedTasks. It keeps checked task items only, along with the items
+// containing them, and removes everything else.
+func PruneOpenTasks(nodes []ast.Node) ([]ast.Node, []ast.Node) {
+	return pruneTasks(nodes, func(task *extast.TaskCheckBox) bool {
+		return task != nil && task.IsChecked
+	})
+}
+
+// pruneTasks removes list items for which keep returns false unless they contain an item to keep.
+// keep receives nil for items without a checkbox and for nodes other than lists.
+func pruneTasks(nodes []ast.Node, keep func(*extast.TaskCheckBox) bool) ([]ast.Node, []ast.Node) {
+	var kept, removed []ast.Node
+	for _, n := range nodes {
+		l, ok := n.(*ast.List)
+		if !ok {
+			if keep(nil) {
+				kept = append(kept, n)
+			} else {
+				removed = append(removed, n)
+			}
+			continue
+		}
+
+		removed = append(removed, pruneList(l, keep)...)
+		if l.ChildCount() == 0 {
+			removed = append(removed, l)
+		} else {
+			kept = append(kept, l)
+		}
+	}
+	return kept, removed
+}
+
+// pruneList removes items from the list, and returns the removed items
+func pruneList(l *ast.List, keep func(*extast.TaskCheckBox) bool) []ast.Node {
+	var removed []ast.Node
+	for c := l.FirstChild(); c != nil; {
+		next := c.NextSibling()
+
+		// prune nested lists first so that an item is kept only while it has something to keep inside
+		var keepNested bool
+		for cc := c.FirstChild(); cc != nil; {
+			nextcc := cc.NextSibling()
+			if nested, ok := cc.(*ast.List); ok {
+				removed = append(removed, pruneList(nested, keep)...)
+				if nested.ChildCount() == 0 {
+					c.RemoveChild(c, nested)
+					removed = append(removed, nested)
+				} else {
+					keepNested = true
+				}
+			}
+			cc = nextcc
+		}
+
+		if !keepNested && !keep(TaskCheckBoxOf(c)) {
+			l.RemoveChild(l, c)
+			removed = append(removed, c)
+		}
+		c = next
+	}
+	return removed
+}
+
+// TaskCheckBoxOf returns the checkbox of the list item, or nil if the item is not a task
+func TaskCheckBoxOf(li ast.Node) *extast.TaskCheckBox {
+	first := li.FirstChild()
+	if first == nil {
+		return nil
+	}
+	if t, ok := first.FirstChild().(*extast.TaskCheckBox); ok {
+		return t
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneTasks(t *testing.T) {
+	source := `## todos
+
+- [ ] open
+- [x] done
+- [x] done with open sub task
+  - [x] done sub task
+  - [ ] open sub task
+- [ ] open with done sub tasks
+  - [x] done sub task
+- plain item`
+
+	tests := []struct {
+		name  string
+		prune func([]byte) string
+		want  string
+	}{
+		{
+			name: "PruneCheckedTasks",
+			prune: func(b []byte) string {
+				gmw := NewGoldmarkWrapper()
+				_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(b, NewHeading(2, "todos"))
+				kept, _ := PruneCheckedTasks(nodes)
+				buf := new(bytes.Buffer)
+				gmw.RenderSlice(buf, b, kept)
+				return buf.String()
+			},
+			want: `
+
+- [ ] open
+- [x] done with open sub task
+  - [ ] open sub task
+- [ ] open with done sub tasks
+- plain item`,
+		},
+		{
+			name: "PruneOpenTasks",
+			prune: func(b []byte) string {
+				gmw := NewGoldmarkWrapper()
+				_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(b, NewHeading(2, "todos"))
+				kept, _ := PruneOpenTasks(nodes)
+				buf := new(bytes.Buffer)
+				gmw.RenderSlice(buf, b, kept)
+				return buf.String()
+			},
+			want: `
+
+- [x] done
+- [x] done with open sub task
+  - [x] done sub task
+- [ ] open with done sub tasks
+  - [x] done sub task`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tt.want, tt.prune([]byte(source)))
+		})
+	}
+}
+
+func TestPruneTasks_allRemoved(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("## todos\n\n- [x] done\n\nsome paragraph")
+
+	gmw := NewGoldmarkWrapper()
+	_, nodes, _ := gmw.FindHeadingAndGetHangingNodes(source, NewHeading(2, "todos"))
+	kept, removed := PruneOpenTasks(nodes)
+
+	assert.Len(kept, 1)
+	assert.Len(removed, 1) // the paragraph
+}
@@ -0,0 +1,9 @@
+package models
+
+type InheritMode string
+
+const (
+	INHERITMODE_COPY InheritMode = "copy" // copy items from the previous memo
+	INHERITMODE_MOVE InheritMode = "move" // copy items from the previous memo, then remove them from there
+	INHERITMODE_LINK InheritMode = "link" // insert a link to the section of the previous memo
+)
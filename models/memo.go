@@ -11,25 +11,37 @@ import (
 type Memo struct {
 	Filepath string
 	Title    string
+	Tag      string // anchor of the title heading in the file
 	Content  string
 }
 
-func NewMemo(filepath, title, content string) *Memo {
+func NewMemo(filepath, title, tag, content string) *Memo {
 	return &Memo{
 		Filepath: filepath,
 		Title:    title,
+		Tag:      tag,
 		Content:  content,
 	}
 }
 
 // SearchKey returns a key to search for a link in the memo content.
 func (m *Memo) SearchKey() string {
-	return filepath.Base(m.Filepath) + "#" + markdown.Text2tag(m.Title)
+	return filepath.Base(m.Filepath) + "#" + m.Tag
+}
+
+// SearchKeys returns keys to search for a link in the memo content: the search key,
+// and the one with the anchor links were written with before slug styles, so that those links are still found.
+func (m *Memo) SearchKeys() []string {
+	keys := []string{m.SearchKey()}
+	if legacy := filepath.Base(m.Filepath) + "#" + markdown.Text2tag(m.Title); legacy != keys[0] {
+		keys = append(keys, legacy)
+	}
+	return keys
 }
 
 // Link returns a relative path to the memo file with the title as a tag.
 func (m *Memo) Link() string {
-	link := ".." + string(os.PathSeparator) + m.Filepath + "#" + markdown.Text2tag(m.Title)
+	link := ".." + string(os.PathSeparator) + m.Filepath + "#" + m.Tag
 	return link
 }
 
@@ -1,7 +1,16 @@
 package models
 
+import "slices"
+
 type MemoArchive struct {
 	Text        string
 	Destination string
+	Aliases     []string // destinations the memo archive was linked with before, such as with anchors of older slug styles
 	Checked     bool
+	Annotation  string // text written after the link in the index, kept when the index is regenerated
+}
+
+// LinkedAs reports whether the destination is of the memo archive, either as it is or as it was linked before
+func (ma MemoArchive) LinkedAs(destination string) bool {
+	return ma.Destination == destination || slices.Contains(ma.Aliases, destination)
 }
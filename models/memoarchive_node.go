@@ -13,4 +13,5 @@ type MemoArchiveNode struct {
 	MemoArchive MemoArchive
 	Text        string
 	Depth       int
+	Category    string // directory relative to memo archives dir, followed by the level 1 heading for titles and memos
 }
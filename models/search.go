@@ -0,0 +1,15 @@
+package models
+
+import "strings"
+
+type SearchHit struct {
+	Filepath    string
+	HeadingPath []string
+	Line        int // 1-based
+	Text        string
+}
+
+// Location returns the heading path of the hit joined by " > ". e.g. "memos > deploy notes"
+func (h *SearchHit) Location() string {
+	return strings.Join(h.HeadingPath, " > ")
+}
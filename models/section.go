@@ -0,0 +1,26 @@
+package models
+
+import "github.com/hirotoni/memo/markdown"
+
+type SectionBehavior string
+
+const (
+	SECTIONBEHAVIOR_STATIC    SectionBehavior = "static"    // left as written in the template
+	SECTIONBEHAVIOR_INHERIT   SectionBehavior = "inherit"   // inherits items from the previous memo
+	SECTIONBEHAVIOR_ARCHIVE   SectionBehavior = "archive"   // shows the memo archive picked for the day
+	SECTIONBEHAVIOR_GENERATED SectionBehavior = "generated" // shows the date of the memo
+)
+
+// Section is a section of daily memos
+type Section struct {
+	Heading     string          `toml:"heading"`               // heading text
+	Level       int             `toml:"level"`                 // heading level. defaults to 2
+	Behavior    SectionBehavior `toml:"behavior"`              // static, inherit, archive or generated. defaults to static
+	Mode        InheritMode     `toml:"mode,omitempty"`        // how to inherit: copy, move or link. defaults to copy
+	KeepChecked bool            `toml:"keepchecked,omitempty"` // inherit checked todos as well
+	Memos       bool            `toml:"memos,omitempty"`       // headings right under this section are memos, listed in weekly reports and searched for links
+}
+
+func (s Section) MarkdownHeading() markdown.Heading {
+	return markdown.NewHeading(s.Level, s.Heading)
+}
@@ -4,6 +4,7 @@ import "github.com/hirotoni/memo/markdown"
 
 type Template struct {
 	Headings []markdown.Heading
+	Body     string // text after the last heading
 }
 
 func NewTemplate(headings []markdown.Heading) Template {
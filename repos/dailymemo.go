@@ -1,18 +1,18 @@
 package repos
 
 import (
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/hirotoni/memo/components"
 	"github.com/hirotoni/memo/configs"
 	"github.com/hirotoni/memo/markdown"
-
 	"github.com/hirotoni/memo/models"
+	"github.com/yuin/goldmark/ast"
 )
 
 type DailymemoRepo struct {
@@ -25,15 +25,13 @@ func NewDailymemoRepo(config *configs.TomlConfig) *DailymemoRepo {
 	}
 }
 
-var FILENAME_REGEX = `\d{4}-\d{2}-\d{2}-\S{3}\.md`
-
 func (repo *DailymemoRepo) Entry(fpath string) (*models.Dailymemo, error) {
 	basename := filepath.Base(fpath)
 	datestring, found := strings.CutSuffix(basename, ".md")
 	if !found {
 		log.Fatal("failed to cut suffix.")
 	}
-	date, err := time.Parse(FULL_LAYOUT, datestring)
+	date, err := repo.config.DateLayout().Parse(datestring, time.Local)
 	if err != nil {
 		return nil, err
 	}
@@ -51,17 +49,9 @@ func (repo *DailymemoRepo) Entry(fpath string) (*models.Dailymemo, error) {
 }
 
 func (repo *DailymemoRepo) Entries() ([]*models.Dailymemo, error) {
-	entries, err := os.ReadDir(repo.config.DailymemoDir()) // sorted by filename(=date)
+	wantfiles, err := repo.Filepaths()
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	wantfiles := make([]string, 0, len(entries))
-	reg := regexp.MustCompile(FILENAME_REGEX)
-	for _, file := range entries {
-		if reg.MatchString(file.Name()) {
-			wantfiles = append(wantfiles, filepath.Join(repo.config.DailymemoDir(), file.Name()))
-		}
+		return nil, err
 	}
 
 	dms := make([]*models.Dailymemo, 0, len(wantfiles))
@@ -72,18 +62,33 @@ func (repo *DailymemoRepo) Entries() ([]*models.Dailymemo, error) {
 		}
 		dms = append(dms, dm)
 	}
+	slices.SortStableFunc(dms, func(a, b *models.Dailymemo) int {
+		return a.Date.Compare(*b.Date)
+	})
 
 	return dms, nil
 }
 
-var FULL_LAYOUT = "2006-01-02-Mon"
-
-func (repo *DailymemoRepo) FindByDate(date string) (*models.Dailymemo, error) {
-	_, err := time.Parse(FULL_LAYOUT, date)
+// Filepaths returns paths of daily memo files without reading them
+func (repo *DailymemoRepo) Filepaths() ([]string, error) {
+	entries, err := os.ReadDir(repo.config.DailymemoDir()) // sorted by filename(=date)
 	if err != nil {
 		return nil, err
 	}
-	filepath := filepath.Join(repo.config.DailymemoDir(), date+".md")
+
+	wantfiles := make([]string, 0, len(entries))
+	reg := repo.config.DateLayout().FilenameRegexp()
+	for _, file := range entries {
+		if reg.MatchString(file.Name()) {
+			wantfiles = append(wantfiles, filepath.Join(repo.config.DailymemoDir(), file.Name()))
+		}
+	}
+	return wantfiles, nil
+}
+
+// FindByDate finds the daily memo of the date
+func (repo *DailymemoRepo) FindByDate(date time.Time) (*models.Dailymemo, error) {
+	filepath := filepath.Join(repo.config.DailymemoDir(), repo.config.DateLayout().Format(date)+".md")
 	dm, err := repo.Entry(filepath)
 	if err != nil {
 		return nil, err
@@ -91,6 +96,11 @@ func (repo *DailymemoRepo) FindByDate(date string) (*models.Dailymemo, error) {
 	return dm, nil
 }
 
+// Save writes the content of the daily memo to its file
+func (repo *DailymemoRepo) Save(dm *models.Dailymemo) error {
+	return os.WriteFile(dm.Filepath, dm.Content, 0644)
+}
+
 func (repo *DailymemoRepo) Template() (*models.Dailymemo, error) {
 	b, err := os.ReadFile(repo.config.DailymemoTemplateFile())
 	if err != nil {
@@ -105,37 +115,78 @@ func (repo *DailymemoRepo) Template() (*models.Dailymemo, error) {
 	return dm, nil
 }
 
+// TemplateFilepaths returns paths of the default template and the templates for specific days
+func (repo *DailymemoRepo) TemplateFilepaths() ([]string, error) {
+	name := strings.TrimSuffix(configs.FILE_NAME_DAILYMEMO_TEMPLATE, ".md")
+	overrides, err := filepath.Glob(filepath.Join(repo.config.DailymemoDir(), name+".*.md"))
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{repo.config.DailymemoTemplateFile()}, overrides...), nil
+}
+
+// TemplateFor returns the template for the date. Templates for the day of month and the weekday take precedence over the default one.
+func (repo *DailymemoRepo) TemplateFor(date time.Time) (*models.Dailymemo, error) {
+	for _, fpath := range repo.config.DailymemoTemplateFiles(date) {
+		b, err := os.ReadFile(fpath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dm := &models.Dailymemo{
+			Filepath: fpath,
+			BaseName: filepath.Base(fpath),
+			Date:     nil,
+			Content:  b,
+		}
+		return dm, nil
+	}
+	return nil, os.ErrNotExist
+}
+
 func (repo *DailymemoRepo) MemosFromDailymemo(dm *models.Dailymemo) []*models.Memo {
-	// find memo block
-	gmw := repo.config.Gmw
-	_, b := gmw.FindHeadingAndGetHangingNodes(dm.Content, components.HEADING_NAME_MEMOS)
-	sb := new(strings.Builder)
-	gmw.RenderSlice(sb, dm.Content, b)
+	memosSection, ok := repo.config.MemosSection()
+	if !ok {
+		return nil
+	}
 
-	memoBlock := []byte(sb.String())
+	relpath, err := filepath.Rel(repo.config.BaseDir, dm.Filepath)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// extract titles
-	_, memoHeadings := gmw.GetHeadingNodesByLevel(memoBlock, components.HEADING_NAME_MEMOS.Level+1)
-	var titles []string
-	for _, heading := range memoHeadings {
-		text := heading.Lines().Value(memoBlock)
-		titles = append(titles, strings.TrimSpace(string(text)))
+	// find memo block
+	gmw := repo.config.Gmw
+	doc := gmw.NewDocument(dm.Content)
+	_, memoBlock, err := doc.HangingNodes(memosSection.MarkdownHeading())
+	if errors.Is(err, markdown.ErrAmbiguousHeading) {
+		log.Printf("skipped %s: %v", dm.BaseName, err)
+		return nil
 	}
+	slugs := doc.HeadingSlugs(repo.config.Slugger())
 
-	// extract each memo block
+	// extract each memo block under the headings one level below
 	var memos []*models.Memo
-	for _, title := range titles {
-		hhh := markdown.NewHeading(components.HEADING_NAME_MEMOS.Level+1, title)
-		_, b := gmw.FindHeadingAndGetHangingNodes(memoBlock, hhh)
-		sb := new(strings.Builder)
-		gmw.RenderSlice(sb, memoBlock, b)
-		relpath, err := filepath.Rel(repo.config.BaseDir, dm.Filepath)
-		if err != nil {
-			log.Fatal(err)
+	for i, n := range memoBlock {
+		heading, ok := n.(*ast.Heading)
+		if !ok || heading.Level != memosSection.Level+1 {
+			continue
+		}
+		var b []ast.Node
+		for _, c := range memoBlock[i+1:] {
+			if next, ok := c.(*ast.Heading); ok && next.Level <= heading.Level {
+				break
+			}
+			b = append(b, c)
 		}
+		sb := new(strings.Builder)
+		gmw.RenderSlice(sb, dm.Content, b)
 
 		if sb.Len() > 0 {
-			mm := models.NewMemo(relpath, title, sb.String())
+			title := strings.TrimSpace(string(heading.Lines().Value(dm.Content)))
+			mm := models.NewMemo(relpath, title, slugs[heading], sb.String())
 			memos = append(memos, mm)
 		}
 	}
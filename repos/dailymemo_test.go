@@ -1,10 +1,15 @@
 package repos
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hirotoni/memo/configs"
 	"github.com/hirotoni/memo/markdown"
+	"github.com/hirotoni/memo/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -65,7 +70,7 @@ func TestDailymemoRepo_FindByDate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			md, err := repo.FindByDate("2024-12-30-Mon")
+			md, err := repo.FindByDate(time.Date(2024, 12, 30, 0, 0, 0, 0, time.Local))
 			assert.Nil(err)
 			assert.Equal("testdata/dailymemo/2024-12-30-Mon.md", md.Filepath)
 		})
@@ -93,3 +98,81 @@ func TestDailymemoRepo_MemosFromDailymemo(t *testing.T) {
 		})
 	}
 }
+
+func TestDailymemoRepo_TemplateFor(t *testing.T) {
+	config := configs.NewTomlConfig("testdata", 7, markdown.NewGoldmarkWrapper())
+	repo := NewDailymemoRepo(config)
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected string
+	}{
+		{name: "day of month", date: time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local), expected: "template.day01.md"},
+		{name: "day of month over weekday", date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local), expected: "template.day01.md"},
+		{name: "weekday", date: time.Date(2024, 12, 30, 0, 0, 0, 0, time.Local), expected: "template.mon.md"},
+		{name: "default", date: time.Date(2024, 12, 31, 0, 0, 0, 0, time.Local), expected: "template.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm, err := repo.TemplateFor(tt.date)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, dm.BaseName)
+		})
+	}
+}
+
+// BenchmarkDailymemoRepo_MemosFromDailymemo extracts memos from a vault of three years of daily memos
+func BenchmarkDailymemoRepo_MemosFromDailymemo(b *testing.B) {
+	config := configs.NewTomlConfig("testdata", 7, markdown.NewGoldmarkWrapper())
+	repo := NewDailymemoRepo(config)
+
+	var dms []*models.Dailymemo
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)
+	for date := start; date.Before(start.AddDate(3, 0, 0)); date = date.AddDate(0, 0, 1) {
+		var sb strings.Builder
+		sb.WriteString("# daily memo\n\n## todos\n\n- [ ] open\n- [x] done\n\n## memos\n")
+		for i := range 5 {
+			fmt.Fprintf(&sb, "\n### memo %d\n\nsome *notes* with a [link](https://example.com)\n\n- item\n- item\n", i)
+		}
+		dms = append(dms, &models.Dailymemo{
+			Filepath: filepath.Join("testdata", "dailymemo", date.Format("2006-01-02-Mon.md")),
+			Date:     &date,
+			Content:  []byte(sb.String()),
+		})
+	}
+
+	b.Run("reparse", func(b *testing.B) {
+		// the memo block is rendered and parsed again for the titles, then for each of them
+		gmw := config.Gmw
+		memosSection, _ := config.MemosSection()
+		for range b.N {
+			for _, dm := range dms {
+				_, nodes, err := gmw.FindHeadingAndGetHangingNodes(dm.Content, memosSection.MarkdownHeading())
+				if err != nil {
+					b.Fatal(err)
+				}
+				sb := new(strings.Builder)
+				gmw.RenderSlice(sb, dm.Content, nodes)
+				memoBlock := []byte(sb.String())
+
+				_, headings := gmw.GetHeadingNodesByLevel(memoBlock, memosSection.Level+1)
+				for _, heading := range headings {
+					title := strings.TrimSpace(string(heading.Lines().Value(memoBlock)))
+					_, nodes, err := gmw.FindHeadingAndGetHangingNodes(memoBlock, markdown.NewHeading(memosSection.Level+1, title))
+					if err != nil {
+						b.Fatal(err)
+					}
+					gmw.RenderSlice(new(strings.Builder), memoBlock, nodes)
+				}
+			}
+		}
+	})
+	b.Run("document", func(b *testing.B) {
+		for range b.N {
+			for _, dm := range dms {
+				repo.MemosFromDailymemo(dm)
+			}
+		}
+	})
+}
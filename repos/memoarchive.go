@@ -3,6 +3,7 @@ package repos
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/hirotoni/memo/configs"
 	"github.com/hirotoni/memo/models"
@@ -46,6 +47,7 @@ func (repo *MemoArchiveRepo) MemoArchivesFromIndex() []*models.MemoArchive {
 				if t.Text == "" && t.Destination == "" {
 					return ast.WalkContinue, nil
 				}
+				t.Annotation = annotation(n, b, t.Destination)
 				memoarchives = append(memoarchives, t)
 			}
 		}
@@ -59,6 +61,26 @@ func (repo *MemoArchiveRepo) MemoArchivesFromIndex() []*models.MemoArchive {
 	return memoarchives
 }
 
+// annotation returns the text after the link to the destination in the text block, with its lines separated by newlines
+func annotation(n ast.Node, source []byte, destination string) string {
+	var lines []string
+	for i := 0; i < n.Lines().Len(); i++ {
+		seg := n.Lines().At(i)
+		lines = append(lines, strings.TrimSpace(string(seg.Value(source))))
+	}
+	raw := strings.Join(lines, "\n")
+
+	_, after, found := strings.Cut(raw, "]("+destination)
+	if !found {
+		return ""
+	}
+	_, after, found = strings.Cut(after, ")")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(after)
+}
+
 func (repo *MemoArchiveRepo) MemoArchivesFromIndexChecked() []*models.MemoArchive {
 	memoarchives := repo.MemoArchivesFromIndex()
 	return filter(memoarchives, func(t *models.MemoArchive) bool { return t.Checked })
@@ -1,6 +1,7 @@
 package repos
 
 import (
+	"errors"
 	"io/fs"
 	"log"
 	"os"
@@ -27,74 +28,72 @@ func NewMemoArchiveNodeRepo(config *configs.TomlConfig) *MemoArchiveNodeRepo {
 func (repo *MemoArchiveNodeRepo) MemoArchiveNodesFromMemoArchivesDir(shown []*models.MemoArchive) []*models.MemoArchiveNode {
 	var tns []*models.MemoArchiveNode
 
-	err := filepath.WalkDir(repo.config.MemoArchivesDir(), func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == repo.config.MemoArchivesTemplateFile() || path == repo.config.MemoArchivesIndexFile() {
-			return nil
-		}
-
-		relpath, err := filepath.Rel(repo.config.DailymemoDir(), path)
-		if err != nil {
-			log.Fatal(err)
-		}
-		pathlist := strings.Split(relpath, string(filepath.Separator))
-		depth := len(pathlist) - 3 // TODO avoid using magic number
+	err := repo.walk(func(path, relpath string, d fs.DirEntry) error {
+		depth := strings.Count(relpath, "/")
 
 		if d.IsDir() {
-			if path == repo.config.MemoArchivesDir() {
+			tmp := models.MemoArchiveNode{
+				Kind:     models.MEMOARCHIVENODEKIND_DIR,
+				Text:     d.Name(),
+				Depth:    depth,
+				Category: repo.category(path, ""),
+			}
+			tns = append(tns, &tmp)
+
+		} else {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			h1, h2s, slugs, err := repo.getMemoArchivesHeadings(b)
+			if err != nil {
+				log.Printf("skipped %s: %v", relpath, err)
+				return nil
+			}
+			if h1 == nil || h2s == nil {
 				return nil
 			}
 
+			link := repo.destination(path)
+			category := repo.category(filepath.Dir(path), string(h1.Text(b)))
 			tmp := models.MemoArchiveNode{
-				Kind:  models.MEMOARCHIVENODEKIND_DIR,
-				Text:  d.Name(),
-				Depth: depth,
+				Kind:     models.MEMOARCHIVENODEKIND_TITLE,
+				Text:     string(h1.Text(b)),
+				Depth:    depth,
+				Category: category,
+				MemoArchive: models.MemoArchive{
+					Text:        string(h1.Text(b)),
+					Destination: link,
+				},
 			}
 			tns = append(tns, &tmp)
 
-		} else {
-			if filepath.Ext(d.Name()) == ".md" {
-				b, err := os.ReadFile(path)
-				if err != nil {
-					log.Fatal(err)
+			for _, h2 := range h2s {
+				ma := models.MemoArchive{
+					Text:        string(h2.Text(b)),
+					Destination: link + "#" + slugs[h2],
 				}
-
-				h1, h2s := repo.getMemoArchivesHeadings(b)
-				if h1 == nil || h2s == nil {
-					return nil
+				// the index and the review state may have destinations without %20 escaping or with anchors of Text2tag
+				for _, l := range []string{link, strings.ReplaceAll(link, "%20", " ")} {
+					for _, anchor := range []string{slugs[h2], markdown.Text2tag(ma.Text)} {
+						if alias := l + "#" + anchor; alias != ma.Destination && !slices.Contains(ma.Aliases, alias) {
+							ma.Aliases = append(ma.Aliases, alias)
+						}
+					}
 				}
+				ma.Checked = slices.ContainsFunc(shown, func(t *models.MemoArchive) bool {
+					return ma.LinkedAs(t.Destination)
+				})
 
 				tmp := models.MemoArchiveNode{
-					Kind:  models.MEMOARCHIVENODEKIND_TITLE,
-					Text:  string(h1.Text(b)),
-					Depth: depth,
-					MemoArchive: models.MemoArchive{
-						Text:        string(h1.Text(b)),
-						Destination: relpath,
-					},
+					Kind:        models.MEMOARCHIVENODEKIND_MEMO,
+					Text:        ma.Text,
+					Depth:       depth + 1,
+					Category:    category,
+					MemoArchive: ma,
 				}
 				tns = append(tns, &tmp)
-
-				for _, h2 := range h2s {
-					destination := relpath + "#" + markdown.Text2tag(string(h2.Text(b)))
-					checked := slices.ContainsFunc(shown, func(t *models.MemoArchive) bool {
-						return t.Destination == destination
-					})
-
-					tmp := models.MemoArchiveNode{
-						Kind:  models.MEMOARCHIVENODEKIND_MEMO,
-						Text:  string(h2.Text(b)),
-						Depth: depth + 1,
-						MemoArchive: models.MemoArchive{
-							Text:        string(h2.Text(b)),
-							Destination: destination,
-							Checked:     checked,
-						},
-					}
-					tns = append(tns, &tmp)
-				}
 			}
 		}
 		return nil
@@ -106,19 +105,99 @@ func (repo *MemoArchiveNodeRepo) MemoArchiveNodesFromMemoArchivesDir(shown []*mo
 
 	return tns
 }
-func (repo *MemoArchiveNodeRepo) getMemoArchivesHeadings(b []byte) (ast.Node, []ast.Node) {
-	_, headings := repo.config.Gmw.GetHeadingNodesByLevel(b, 1)
+
+// walk calls the function for the directories and the markdown files in memo archives dir in lexical order, with their paths relative to the dir.
+// The template, the index, and the files and directories the config skips are not walked.
+func (repo *MemoArchiveNodeRepo) walk(fn func(path, relpath string, d fs.DirEntry) error) error {
+	root := repo.config.MemoArchivesDir()
+	if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root || path == repo.config.MemoArchivesTemplateFile() || path == repo.config.MemoArchivesIndexFile() {
+			return nil
+		}
+
+		relpath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relpath = filepath.ToSlash(relpath)
+		if repo.config.MemoArchives.Skips(relpath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && filepath.Ext(d.Name()) != ".md" {
+			return nil
+		}
+		return fn(path, relpath, d)
+	})
+}
+
+// destination returns the link to the file from daily memos, which works from the index as well since it is in a sibling dir
+func (repo *MemoArchiveNodeRepo) destination(path string) string {
+	relpath, err := filepath.Rel(repo.config.DailymemoDir(), path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(relpath), " ", "%20")
+}
+
+// category returns the directory relative to memo archives dir, followed by the title if any: e.g. go/concurrency/Go notes
+func (repo *MemoArchiveNodeRepo) category(dir, title string) string {
+	rel, err := filepath.Rel(repo.config.MemoArchivesDir(), dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var segments []string
+	if rel != "." {
+		segments = strings.Split(filepath.ToSlash(rel), "/")
+	}
+	if title != "" {
+		segments = append(segments, title)
+	}
+	return strings.Join(segments, "/")
+}
+
+// MemoArchiveFiles returns paths of the markdown files in memo archives dir, except for the template, the index and the ones the config skips
+func (repo *MemoArchiveNodeRepo) MemoArchiveFiles() ([]string, error) {
+	var files []string
+	err := repo.walk(func(path, relpath string, d fs.DirEntry) error {
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// getMemoArchivesHeadings returns the first level 1 heading, the level 2 headings under it, and the slugs of the headings.
+// It returns ErrAmbiguousHeading if another level 1 heading has the same text as the first one.
+func (repo *MemoArchiveNodeRepo) getMemoArchivesHeadings(b []byte) (ast.Node, []ast.Node, map[ast.Node]string, error) {
+	doc := repo.config.Gmw.NewDocument(b)
+	headings := doc.HeadingsByLevel(1)
 	if len(headings) == 0 {
-		return nil, nil
+		return nil, nil, nil, nil
 	}
 	heading := headings[0]
-	heading1, nodes := repo.config.Gmw.FindHeadingAndGetHangingNodes(b, markdown.Heading{Level: 1, Text: string(heading.Text(b))})
+	heading1, nodes, err := doc.HangingNodes(markdown.Heading{Level: 1, Text: string(heading.Text(b))})
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	heading2s := filter(nodes, func(n ast.Node) bool {
 		h, ok := n.(*ast.Heading)
 		return ok && h.Level == 2
 	})
 
-	return heading1, heading2s
-
+	return heading1, heading2s, doc.HeadingSlugs(repo.config.Slugger()), nil
 }
@@ -38,22 +38,22 @@ func TestMemoArchiveNodeRepo_MemoArchiveNodesFromMemoArchivesDir(t *testing.T) {
 			},
 			want: []*models.MemoArchiveNode{
 				{
-					Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "testmemoarchives", Depth: 0,
+					Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "testmemoarchives", Depth: 0, Category: "testmemoarchives",
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1,
+					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "testmemoarchive", Destination: "../memoarchives/testmemoarchives/testmemoarchive-1.md", Checked: false},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2,
+					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "super duper pepper", Destination: "../memoarchives/testmemoarchives/testmemoarchive-1.md#super-duper-pepper", Checked: false},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1,
+					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "testmemoarchive", Destination: "../memoarchives/testmemoarchives/testmemoarchive-2.md", Checked: false},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2,
+					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "super duper pepper", Destination: "../memoarchives/testmemoarchives/testmemoarchive-2.md#super-duper-pepper", Checked: false},
 				},
 			},
@@ -71,22 +71,22 @@ func TestMemoArchiveNodeRepo_MemoArchiveNodesFromMemoArchivesDir(t *testing.T) {
 			},
 			want: []*models.MemoArchiveNode{
 				{
-					Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "testmemoarchives", Depth: 0,
+					Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "testmemoarchives", Depth: 0, Category: "testmemoarchives",
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1,
+					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "testmemoarchive", Destination: "../memoarchives/testmemoarchives/testmemoarchive-1.md", Checked: false},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2,
+					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "super duper pepper", Destination: "../memoarchives/testmemoarchives/testmemoarchive-1.md#super-duper-pepper", Checked: true},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1,
+					Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "testmemoarchive", Depth: 1, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "testmemoarchive", Destination: "../memoarchives/testmemoarchives/testmemoarchive-2.md", Checked: false},
 				},
 				{
-					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2,
+					Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "super duper pepper", Depth: 2, Category: "testmemoarchives/testmemoarchive",
 					MemoArchive: models.MemoArchive{Text: "super duper pepper", Destination: "../memoarchives/testmemoarchives/testmemoarchive-2.md#super-duper-pepper", Checked: false},
 				},
 			},
@@ -100,3 +100,58 @@ func TestMemoArchiveNodeRepo_MemoArchiveNodesFromMemoArchivesDir(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoArchiveNodeRepo_MemoArchiveNodesFromMemoArchivesDir_root(t *testing.T) {
+	assert := assert.New(t)
+	testConfig := configs.LoadTomlConfig()
+	testConfig.BaseDir = "testdata"
+	testConfig.MemoArchives = configs.MemoArchivesConfig{
+		Root:    "knowledgebase",
+		Exclude: []string{"drafts", "**/assets"},
+	}
+	repo := NewMemoArchiveNodeRepo(testConfig)
+
+	want := []*models.MemoArchiveNode{
+		{Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "go", Depth: 0, Category: "go"},
+		{Kind: models.MEMOARCHIVENODEKIND_DIR, Text: "concurrency", Depth: 1, Category: "go/concurrency"},
+		{
+			Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "Channels", Depth: 2, Category: "go/concurrency/Channels",
+			MemoArchive: models.MemoArchive{Text: "Channels", Destination: "../knowledgebase/go/concurrency/channels.md"},
+		},
+		{
+			Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "select", Depth: 3, Category: "go/concurrency/Channels",
+			MemoArchive: models.MemoArchive{Text: "select", Destination: "../knowledgebase/go/concurrency/channels.md#select"},
+		},
+		{
+			Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "Buffered channels?", Depth: 3, Category: "go/concurrency/Channels",
+			MemoArchive: models.MemoArchive{
+				Text:        "Buffered channels?",
+				Destination: "../knowledgebase/go/concurrency/channels.md#buffered-channels",
+				Aliases:     []string{"../knowledgebase/go/concurrency/channels.md#Buffered-channels?"},
+				Checked:     true, // shown in the index with the anchor of Text2tag
+			},
+		},
+		{
+			Kind: models.MEMOARCHIVENODEKIND_TITLE, Text: "Top", Depth: 0, Category: "Top",
+			MemoArchive: models.MemoArchive{Text: "Top", Destination: "../knowledgebase/top%20level.md"},
+		},
+		{
+			Kind: models.MEMOARCHIVENODEKIND_MEMO, Text: "intro", Depth: 1, Category: "Top",
+			MemoArchive: models.MemoArchive{
+				Text:        "intro",
+				Destination: "../knowledgebase/top%20level.md#intro",
+				Aliases:     []string{"../knowledgebase/top level.md#intro"},
+				Checked:     true, // shown in the index before spaces were escaped
+			},
+		},
+	}
+	shown := []*models.MemoArchive{
+		{Destination: "../knowledgebase/go/concurrency/channels.md#Buffered-channels?", Checked: true},
+		{Destination: "../knowledgebase/top level.md#intro", Checked: true},
+	}
+	assert.Equal(want, repo.MemoArchiveNodesFromMemoArchivesDir(shown))
+
+	files, err := repo.MemoArchiveFiles()
+	assert.NoError(err)
+	assert.Equal([]string{"testdata/knowledgebase/go/concurrency/channels.md", "testdata/knowledgebase/top level.md"}, files)
+}
@@ -31,7 +31,7 @@ func TestMemoArchiveRepo_MemoArchivesFromIndex(t *testing.T) {
 			},
 			want: []*models.MemoArchive{
 				{Text: "some memoarchive", Destination: "somewhere", Checked: false},
-				{Text: "another memoarchive", Destination: "anywhere", Checked: true},
+				{Text: "another memoarchive", Destination: "anywhere", Checked: true, Annotation: "tricky, see *the spec*"},
 				{Text: "yet another memoarchive", Destination: "everywhere", Checked: false},
 			},
 		},
@@ -69,7 +69,7 @@ func TestMemoArchiveRepo_MemoArchivesFromIndexChecked(t *testing.T) {
 				gmw:    markdown.NewGoldmarkWrapper(),
 			},
 			want: []*models.MemoArchive{
-				{Text: "another memoarchive", Destination: "anywhere", Checked: true},
+				{Text: "another memoarchive", Destination: "anywhere", Checked: true, Annotation: "tricky, see *the spec*"},
 			},
 		},
 	}
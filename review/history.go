@@ -0,0 +1,72 @@
+package review
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Showing is a record of a memo archive shown in a daily memo
+type Showing struct {
+	Date        string `json:"date"` // YYYY-MM-DD
+	Destination string `json:"destination"`
+	Dailymemo   string `json:"dailymemo"` // filename of the daily memo the memo archive was inserted into
+}
+
+// AppendHistory appends the showings to the history file, one json object per line
+func AppendHistory(path string, showings []Showing) error {
+	if len(showings) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	for _, s := range showings {
+		if err := enc.Encode(s); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// LoadHistory loads the showings from the history file in the order they were appended.
+// A memo archive shown more than once on a day, such as in a regenerated daily memo, counts as the first of them.
+// No showings are returned when the file does not exist.
+func LoadHistory(path string) ([]Showing, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var showings []Showing
+	seen := map[Showing]bool{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s Showing
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if key := (Showing{Date: s.Date, Destination: s.Destination}); !seen[key] {
+			seen[key] = true
+			showings = append(showings, s)
+		}
+	}
+	return showings, scanner.Err()
+}
@@ -0,0 +1,141 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SM-2 parameters
+const (
+	INITIAL_EASE  = 2.5
+	MIN_EASE      = 1.3
+	MAX_GRADE     = 5
+	PASSING_GRADE = 3 // grades below it start the repetitions over
+)
+
+const DATE_LAYOUT = "2006-01-02"
+
+var ErrInvalidGrade = fmt.Errorf("grade must be between 0 and %d", MAX_GRADE)
+
+// Item is the review state of a memo archive
+type Item struct {
+	Ease        float64 `json:"ease"`
+	Interval    int     `json:"interval"`    // days from the last review to the next
+	Repetitions int     `json:"repetitions"` // passing reviews in a row
+	Due         string  `json:"due"`         // date of the next review, YYYY-MM-DD
+}
+
+// Schedule is the review state of memo archives persisted under the base dir
+type Schedule struct {
+	Items map[string]*Item `json:"items"` // destination -> state
+}
+
+func New() *Schedule {
+	return &Schedule{Items: map[string]*Item{}}
+}
+
+// Load loads the schedule from the file. An empty schedule is returned when the file does not exist.
+func Load(path string) (*Schedule, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := New()
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Items == nil {
+		s.Items = map[string]*Item{}
+	}
+	return s, nil
+}
+
+// Save writes the schedule to the file
+func (s *Schedule) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Rename moves the items keyed by old destinations to the new destinations they are mapped to, unless the new ones have items already.
+// It reports whether any item was moved.
+func (s *Schedule) Rename(destinations map[string]string) bool {
+	var renamed bool
+	for old, item := range s.Items {
+		newDest, ok := destinations[old]
+		if !ok {
+			continue
+		}
+		if _, exists := s.Items[newDest]; !exists {
+			s.Items[newDest] = item
+		}
+		delete(s.Items, old)
+		renamed = true
+	}
+	return renamed
+}
+
+// Review records the grade of the memo archive reviewed on the date, from 0 (forgotten) to 5 (perfect), and schedules the next review
+func (s *Schedule) Review(destination string, grade int, date time.Time) (*Item, error) {
+	if grade < 0 || grade > MAX_GRADE {
+		return nil, ErrInvalidGrade
+	}
+	item, ok := s.Items[destination]
+	if !ok {
+		item = &Item{Ease: INITIAL_EASE}
+		s.Items[destination] = item
+	}
+
+	if grade < PASSING_GRADE {
+		item.Repetitions = 0
+		item.Interval = 1
+	} else {
+		item.Repetitions++
+		switch item.Repetitions {
+		case 1:
+			item.Interval = 1
+		case 2:
+			item.Interval = 6
+		default:
+			item.Interval = int(math.Round(float64(item.Interval) * item.Ease))
+		}
+	}
+	q := float64(MAX_GRADE - grade)
+	item.Ease = math.Max(MIN_EASE, item.Ease+0.1-q*(0.08+q*0.02))
+	item.Due = date.AddDate(0, 0, item.Interval).Format(DATE_LAYOUT)
+	return item, nil
+}
+
+// Overdue returns the days the memo archive is past its due date on the date, which is negative if it is not due yet.
+// Memo archives never reviewed are due on any date.
+func (s *Schedule) Overdue(destination string, date time.Time) int {
+	item, ok := s.Items[destination]
+	if !ok {
+		return 0
+	}
+	due, err := time.ParseInLocation(DATE_LAYOUT, item.Due, date.Location())
+	if err != nil {
+		return 0 // review it again to repair the date
+	}
+	y, m, d := date.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+	return int(math.Round(today.Sub(due).Hours() / 24))
+}
@@ -0,0 +1,139 @@
+package review
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var day = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func TestSchedule_Review(t *testing.T) {
+	tests := []struct {
+		name   string
+		grades []int
+		want   Item
+	}{
+		{name: "first pass", grades: []int{4}, want: Item{Ease: 2.5, Interval: 1, Repetitions: 1, Due: "2025-01-02"}},
+		{name: "second pass", grades: []int{4, 4}, want: Item{Ease: 2.5, Interval: 6, Repetitions: 2, Due: "2025-01-07"}},
+		{name: "third pass multiplies by ease", grades: []int{5, 5, 5}, want: Item{Ease: 2.8, Interval: 16, Repetitions: 3, Due: "2025-01-17"}},
+		{name: "fail starts over", grades: []int{5, 5, 1}, want: Item{Ease: 2.16, Interval: 1, Repetitions: 0, Due: "2025-01-02"}},
+		{name: "ease has a floor", grades: []int{0, 0, 0, 0, 0}, want: Item{Ease: 1.3, Interval: 1, Repetitions: 0, Due: "2025-01-02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			s := New()
+			var got *Item
+			for _, g := range tt.grades {
+				var err error
+				got, err = s.Review("a.md", g, day)
+				assert.NoError(err)
+			}
+			assert.InDelta(tt.want.Ease, got.Ease, 1e-9)
+			assert.Equal(tt.want.Interval, got.Interval)
+			assert.Equal(tt.want.Repetitions, got.Repetitions)
+			assert.Equal(tt.want.Due, got.Due)
+		})
+	}
+}
+
+func TestSchedule_Review_invalidGrade(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	for _, g := range []int{-1, 6} {
+		_, err := s.Review("a.md", g, day)
+		assert.ErrorIs(err, ErrInvalidGrade)
+	}
+	assert.Empty(s.Items)
+}
+
+func TestSchedule_Overdue(t *testing.T) {
+	s := New()
+	s.Items["a.md"] = &Item{Due: "2024-12-29"}
+	s.Items["b.md"] = &Item{Due: "2025-01-03"}
+
+	tests := []struct {
+		name        string
+		destination string
+		want        int
+	}{
+		{name: "past due", destination: "a.md", want: 3},
+		{name: "not due yet", destination: "b.md", want: -2},
+		{name: "never reviewed", destination: "c.md", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.Overdue(tt.destination, day))
+		})
+	}
+}
+
+func TestSchedule_Rename(t *testing.T) {
+	assert := assert.New(t)
+	s := New()
+	s.Items["a b.md"] = &Item{Due: "2025-01-02"}
+	s.Items["c d.md"] = &Item{Due: "2025-01-03"}
+	s.Items["c%20d.md"] = &Item{Due: "2025-01-04"}
+	s.Items["e.md"] = &Item{Due: "2025-01-05"}
+
+	assert.True(s.Rename(map[string]string{"a b.md": "a%20b.md", "c d.md": "c%20d.md"}))
+	assert.Equal(map[string]*Item{
+		"a%20b.md": {Due: "2025-01-02"},
+		"c%20d.md": {Due: "2025-01-04"}, // reviewed since it was renamed
+		"e.md":     {Due: "2025-01-05"},
+	}, s.Items)
+	assert.False(s.Rename(map[string]string{"a b.md": "a%20b.md"}))
+}
+
+func TestSchedule_SaveLoad(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), ".memo", "review.json")
+
+	s, err := Load(path)
+	assert.NoError(err)
+	assert.Empty(s.Items)
+
+	_, err = s.Review("a.md", 4, day)
+	assert.NoError(err)
+	assert.NoError(s.Save(path))
+
+	loaded, err := Load(path)
+	assert.NoError(err)
+	assert.Equal(s, loaded)
+}
+
+func TestHistory(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), ".memo", "history.jsonl")
+
+	got, err := LoadHistory(path)
+	assert.NoError(err)
+	assert.Empty(got)
+
+	first := []Showing{{Date: "2025-01-01", Destination: "a.md", Dailymemo: "2025-01-01-Wed.md"}}
+	second := []Showing{
+		{Date: "2025-01-02", Destination: "b.md", Dailymemo: "2025-01-02-Thu.md"},
+		{Date: "2025-01-02", Destination: "a.md", Dailymemo: "2025-01-02-Thu.md"},
+	}
+	assert.NoError(AppendHistory(path, first))
+	assert.NoError(AppendHistory(path, nil))
+	assert.NoError(AppendHistory(path, second))
+
+	got, err = LoadHistory(path)
+	assert.NoError(err)
+	assert.Equal(append(first, second...), got)
+
+	// a regenerated daily memo appends its showings again, even if it has been renamed since
+	regenerated := []Showing{
+		{Date: "2025-01-01", Destination: "a.md", Dailymemo: "2025-01-01.md"},
+		{Date: "2025-01-01", Destination: "c.md", Dailymemo: "2025-01-01.md"},
+	}
+	assert.NoError(AppendHistory(path, regenerated))
+
+	got, err = LoadHistory(path)
+	assert.NoError(err)
+	assert.Equal(append(append(first, second...), regenerated[1]), got)
+}